@@ -2,36 +2,48 @@ package yelp
 
 import (
 	"bytes"
-	"math/rand"
+	"crypto/rand"
+	"strings"
 )
 
 //The hexMap string is used by the percentEncode function to encode a character
 //that is not allowed to exist as plaintext.
 const hexMap string = "0123456789ABCDEF"
 
+//Encoding specifies how query elements are percent encoded when a request is
+//signed and sent to Yelp.
+//
+//EncodingRFC3986 encodes every name and value as UTF-8 following RFC 3986 and
+//the OAuth 1.0 specification (RFC 5849, section 3.6). This is the default.
+//
+//EncodingYelpLegacy reproduces the encoding of earlier versions of this
+//package: values are sent with spaces replaced by '+' and are only encoded as
+//part of the signature base string, where commas are encoded twice (as %252C)
+//because Yelp expects them that way.
+type Encoding byte
+
+const (
+	EncodingRFC3986 Encoding = iota
+	EncodingYelpLegacy
+)
+
 //shouldPercentEncode returns true if a character should be percent encoded
 func shouldPercentEncode(c byte) bool {
 	return !((c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || c == '-' || c == '.' || c == '_' || c == '~')
 }
 
-//percentEncode will percent encode a provided string and return it.
+//percentEncode will percent encode a provided string following RFC 3986 and
+//return it. Every byte of the UTF-8 representation of the string is encoded
+//separately.
 func percentEncode(source string) string {
 	var buffer bytes.Buffer
 
-	for _, v := range source {
-		val := byte(v)
+	for i := 0; i < len(source); i++ {
+		val := source[i]
 		if shouldPercentEncode(val) {
-			//I know this is the weirdest hack ever. But somehow Yelp does not like
-			//it when it has to percent encode a comma. This should be %2C, but yelp
-			//expects it to be %252C (coincedentally, %25 == '%', maybe a double
-			//URL encoding error on their part?).
-			if val == ',' {
-				buffer.WriteString("%252C")
-			} else {
-				buffer.WriteByte('%')
-				buffer.WriteByte(hexMap[(val>>4)&0x0F])
-				buffer.WriteByte(hexMap[val&0x0F])
-			}
+			buffer.WriteByte('%')
+			buffer.WriteByte(hexMap[(val>>4)&0x0F])
+			buffer.WriteByte(hexMap[val&0x0F])
 		} else {
 			buffer.WriteByte(val)
 		}
@@ -40,17 +52,42 @@ func percentEncode(source string) string {
 	return buffer.String()
 }
 
+//legacyPercentEncode percent encodes a provided string in the same manner as
+//percentEncode, except for commas. I know this is the weirdest hack ever. But
+//somehow Yelp does not like it when it has to percent encode a comma in the
+//legacy encoding. This should be %2C, but yelp expects it to be %252C
+//(coincedentally, %25 == '%'). This is because the values were never encoded
+//before being encoded as part of the signature base string, as RFC 5849
+//requires, and a comma is the only character in the values that matters.
+func legacyPercentEncode(source string) string {
+	return strings.Replace(percentEncode(source), "%2C", "%252C", -1)
+}
+
 //The nonceCharacter string contains all allowed letters that can be used when
 //generating a new nonce string
 const nonceCharacters string = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 const nonceLength int = len(nonceCharacters)
 
-//nonce will return a nonce-string with a specified length in characters
+//A NonceSource returns a new nonce-string with a specified length in
+//characters. Every returned nonce should be unique and unpredictable.
+type NonceSource func(length int) string
+
+//nonce will return a nonce-string with a specified length in characters. The
+//characters are chosen using the cryptographically secure random number
+//generator, without bias towards any of the characters.
 func nonce(length int) string {
 	var result bytes.Buffer
+	random := make([]byte, length)
+
+	for result.Len() < length {
+		rand.Read(random)
 
-	for i := 0; i < length; i++ {
-		result.WriteByte(nonceCharacters[rand.Int()%nonceLength])
+		for _, v := range random {
+			//reject values that would favour the first characters
+			if int(v) < 256-256%nonceLength && result.Len() < length {
+				result.WriteByte(nonceCharacters[int(v)%nonceLength])
+			}
+		}
 	}
 
 	return result.String()
@@ -15,7 +15,12 @@ func TestPercentEncode(t *testing.T) {
 		"abc&abc*abc",
 		"abc(abc)abc",
 		"abc-abc_abc",
-		"abc=abc+abc"}
+		"abc=abc+abc",
+		"abc,abc",
+		"München",
+		"Zürich",
+		"Ā",
+		"\u3001"}
 	expected := []string{"",
 		"abc",
 		"abc%20abc",
@@ -26,7 +31,12 @@ func TestPercentEncode(t *testing.T) {
 		"abc%26abc%2Aabc",
 		"abc%28abc%29abc",
 		"abc-abc_abc",
-		"abc%3Dabc%2Babc"}
+		"abc%3Dabc%2Babc",
+		"abc%2Cabc",
+		"M%C3%BCnchen",
+		"Z%C3%BCrich",
+		"%C4%80",
+		"%E3%80%81"}
 
 	if len(toAttempt) != len(expected) {
 		t.Errorf("Invalid test data supplied: %d attempts unequal to %d expected results", len(toAttempt), len(expected))
@@ -38,3 +48,14 @@ func TestPercentEncode(t *testing.T) {
 		}
 	}
 }
+
+func TestLegacyPercentEncode(t *testing.T) {
+	toAttempt := []string{"bar,cafe", "a%2Cb", "M\u00fcnchen"}
+	expected := []string{"bar%252Ccafe", "a%252Cb", "M%C3%BCnchen"}
+
+	for i := range toAttempt {
+		if legacyPercentEncode(toAttempt[i]) != expected[i] {
+			t.Errorf("legacyPercentEncode('%s') was not equal to '%s'", toAttempt[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package yelp
+
+//The SearchBuilder structure composes a search from consecutive method calls,
+//for example:
+//
+//	businesses, err := yelp.NewSearch().Near("Delft").Terms("bar").
+//		Categories(yelp.SearchCategoryBars).Radius(2000).
+//		SortBy(yelp.SearchSortDistance).Search(client)
+//
+//Every option is validated as soon as it is added. Invalid options are not
+//added, instead their errors are accumulated and reported together by Err(),
+//Query(), Options() and Search(...), which also check the rules involving
+//multiple options (see Validate(...)). A SearchBuilder should be created
+//through NewSearch().
+type SearchBuilder struct {
+	options []SearchQuerier
+	query   SearchQuery
+	errs    ValidationErrors
+}
+
+//NewSearch creates a new and empty SearchBuilder.
+func NewSearch() *SearchBuilder {
+	return &SearchBuilder{}
+}
+
+//With adds the provided options to the search.
+func (b *SearchBuilder) With(options ...SearchQuerier) *SearchBuilder {
+	for _, v := range options {
+		if v == nil {
+			b.errs = append(b.errs, Error{ErrorTypeInvalidArgumentDefinition, "SearchBuilder", "Nil search option"})
+			continue
+		}
+
+		//an option that fails may have been partially added to the query
+		next := b.query.clone()
+
+		if err := v.Query(&next); err != nil {
+			b.errs = append(b.errs, err)
+			continue
+		}
+
+		b.query = next
+		b.options = append(b.options, v)
+	}
+
+	return b
+}
+
+//Near searches near the location with the provided name.
+func (b *SearchBuilder) Near(location string) *SearchBuilder {
+	return b.With(SearchLocation(location))
+}
+
+//NearCoordinates searches near the location with the provided name, where the
+//coordinates are used when the name is ambiguous.
+func (b *SearchBuilder) NearCoordinates(location string, latitude, longitude float64) *SearchBuilder {
+	return b.With(SearchLocationCoordinates{location, latitude, longitude})
+}
+
+//At searches around the provided coordinates.
+func (b *SearchBuilder) At(latitude, longitude float64) *SearchBuilder {
+	return b.With(SearchCoordinates{latitude, longitude})
+}
+
+//Within searches within the provided bounds.
+func (b *SearchBuilder) Within(bounds Bounds) *SearchBuilder {
+	return b.With(bounds.SearchBounds())
+}
+
+//Terms searches for businesses matching the provided terms.
+func (b *SearchBuilder) Terms(terms ...string) *SearchBuilder {
+	return b.With(SearchTerms(terms))
+}
+
+//Categories searches for businesses in any of the provided categories.
+func (b *SearchBuilder) Categories(categories ...SearchCategory) *SearchBuilder {
+	return b.With(SearchCategories(categories))
+}
+
+//Radius limits the search to the provided radius in meters.
+func (b *SearchBuilder) Radius(meters int) *SearchBuilder {
+	return b.With(SearchRadius(meters))
+}
+
+//SortBy sorts the businesses using the provided sorting method.
+func (b *SearchBuilder) SortBy(sort SearchSort) *SearchBuilder {
+	return b.With(sort)
+}
+
+//Limit limits the number of businesses that are returned.
+func (b *SearchBuilder) Limit(limit int) *SearchBuilder {
+	return b.With(SearchLimit(limit))
+}
+
+//Offset skips the provided number of businesses.
+func (b *SearchBuilder) Offset(offset int) *SearchBuilder {
+	return b.With(SearchOffset(offset))
+}
+
+//Deals only searches for businesses offering deals.
+func (b *SearchBuilder) Deals() *SearchBuilder {
+	return b.With(SearchDeals(true))
+}
+
+//Locale sets the country code and language of the results.
+func (b *SearchBuilder) Locale(countryCode, language string) *SearchBuilder {
+	return b.With(SearchCountryCode(countryCode), SearchLanguage(language))
+}
+
+//Param adds an arbitrary query element, see SearchParam(...).
+func (b *SearchBuilder) Param(name, value string) *SearchBuilder {
+	return b.With(SearchParam(name, value))
+}
+
+//Err returns all problems found in the search so far as a ValidationErrors, or
+//nil when the search is valid.
+func (b *SearchBuilder) Err() error {
+	errs := append(append(ValidationErrors(nil), b.errs...), b.query.validate()...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+//Options returns the valid options of the search, which can be passed to
+//Client.SearchOptions(...). An error is returned when the search is invalid.
+func (b *SearchBuilder) Options() ([]SearchQuerier, error) {
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
+
+	return append([]SearchQuerier(nil), b.options...), nil
+}
+
+//Query returns the query of the search, which can be passed to
+//Client.SearchQuery(...). An error is returned when the search is invalid.
+func (b *SearchBuilder) Query() (SearchQuery, error) {
+	if err := b.Err(); err != nil {
+		return SearchQuery{}, err
+	}
+
+	return b.query.clone(), nil
+}
+
+//Search performs the search using the provided client, including its default
+//options. An error is returned without performing a request when the search is
+//invalid.
+func (b *SearchBuilder) Search(c *Client) (*Businesses, error) {
+	options, err := b.Options()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.SearchOptions(options...)
+}
@@ -0,0 +1,45 @@
+package yelp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSearchBuilder(t *testing.T) {
+	q, err := NewSearch().Near("Delft").Terms("bar", "cafe").Categories(SearchCategoryBars).
+		Radius(2000).SortBy(SearchSortDistance).Limit(10).Query()
+	if err != nil {
+		t.Fatalf("Expected the search to be valid, got '%v'", err)
+	}
+
+	if q.String() != "location=Delft&term=bar,cafe&category_filter=bars&radius_filter=2000&sort=1&limit=10" {
+		t.Errorf("Expected the query to contain every option, got '%s'", q.String())
+	}
+
+	//all problems are accumulated
+	b := NewSearch().Near("Delft").At(52, 4.35).Radius(-1).Limit(20).Offset(30).Within(Bounds{Coordinates{51, 4}, Coordinates{52, 5}})
+	var errs ValidationErrors
+	if !errors.As(b.Err(), &errs) || len(errs) != 4 {
+		t.Errorf("Expected 4 problems, got '%v'", b.Err())
+	}
+
+	if _, err = b.Options(); err == nil {
+		t.Errorf("Expected the options of an invalid search to be rejected")
+	}
+
+	//an invalid search is not performed
+	requests := 0
+	c := stubClient(t, func(*Request) (*Response, error) {
+		requests++
+		return &Response{Businesses: &Businesses{Total: 1}}, nil
+	})
+
+	if _, err = b.Search(c); err == nil || requests != 0 {
+		t.Errorf("Expected the invalid search not to be performed")
+	}
+
+	businesses, err := NewSearch().Near("Delft").Deals().Search(c)
+	if err != nil || businesses.Total != 1 || requests != 1 {
+		t.Errorf("Expected the search to be performed, got '%v', '%v'", businesses, err)
+	}
+}
@@ -1,10 +1,48 @@
 package yelp
 
+import (
+	"encoding/json"
+)
+
 //The Coordinates structure represents latitude and longitude coordinates and has
 //JSON tags such that it can be read from the returned yelp data
 type Coordinates struct {
-	Latitude  float64 `json:"latitude" json:"latitude_delta"`
-	Longitude float64 `json:"longitude" json:"longitude_delta"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+//UnmarshalJSON reads coordinates from either the latitude and longitude fields
+//or, as Yelp uses for the span of a region, the latitude_delta and
+//longitude_delta fields.
+func (c *Coordinates) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		Latitude       *float64 `json:"latitude"`
+		Longitude      *float64 `json:"longitude"`
+		LatitudeDelta  *float64 `json:"latitude_delta"`
+		LongitudeDelta *float64 `json:"longitude_delta"`
+	}
+
+	err := json.Unmarshal(data, &fields)
+
+	if err != nil {
+		return err
+	}
+
+	*c = Coordinates{}
+
+	if fields.Latitude != nil {
+		c.Latitude = *fields.Latitude
+	} else if fields.LatitudeDelta != nil {
+		c.Latitude = *fields.LatitudeDelta
+	}
+
+	if fields.Longitude != nil {
+		c.Longitude = *fields.Longitude
+	} else if fields.LongitudeDelta != nil {
+		c.Longitude = *fields.LongitudeDelta
+	}
+
+	return nil
 }
 
 //The BusinessLocation represents the location of a yelp business. It is embedded
@@ -19,16 +57,49 @@ type BusinessLocation struct {
 	StateCode      string      `json:"state_code"`
 }
 
+//The BusinessCategory structure represents a category a yelp business belongs
+//to. Yelp provides each category as a pair of its display name and its alias,
+//the alias being the name used by the SearchCategories search option.
+type BusinessCategory struct {
+	Name  string
+	Alias string
+}
+
+//UnmarshalJSON reads a category from the [name, alias] pair used by Yelp.
+func (bc *BusinessCategory) UnmarshalJSON(data []byte) error {
+	var pair []string
+	err := json.Unmarshal(data, &pair)
+
+	if err != nil {
+		return err
+	}
+
+	if len(pair) != 2 {
+		return Error{ErrorTypeInvalidYelpResponse, "BusinessCategory", "Expected a category to consist of a name and an alias"}
+	}
+
+	bc.Name, bc.Alias = pair[0], pair[1]
+	return nil
+}
+
+//MarshalJSON writes a category as the [name, alias] pair used by Yelp.
+func (bc BusinessCategory) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]string{bc.Name, bc.Alias})
+}
+
 //The Business structure is the complete description of a business as provided
 //by yelp.
 type Business struct {
-	DisplayPhone string            `json:"display_phone"`
-	Distance     float64           `json:"distance"`
-	IsClosed     bool              `json:"is_closed"`
-	Location     *BusinessLocation `json:"location"`
-	Name         string            `json:"name"`
-	Phone        string            `json:"phone"`
-	Rating       float64           `json:"rating"`
+	ID           string             `json:"id"`
+	Categories   []BusinessCategory `json:"categories"`
+	DisplayPhone string             `json:"display_phone"`
+	Distance     float64            `json:"distance"`
+	IsClosed     bool               `json:"is_closed"`
+	Location     *BusinessLocation  `json:"location"`
+	Name         string             `json:"name"`
+	Phone        string             `json:"phone"`
+	Rating       float64            `json:"rating"`
+	ReviewCount  int                `json:"review_count"`
 }
 
 //The BusinessRegion structure specifies the center of the region which is
@@ -16,6 +16,8 @@ Creating an instance of the Query structure. After which consecutive
 calls to the Append(name, value) function will add a new query element.
 Each consists of a element name and a corresponding value. These values
 must follow the rules provided by the Yelp API documentation.
+Elements can be inspected, replaced and removed through Get(name),
+Set(name, value) and Delete(name).
 
 The advantage of this method is that the queries are all performed
 slightly faster. However, these are more error-prone and, in the case
@@ -39,10 +41,35 @@ options are:
 - SearchCategory
 - SearchRadius
 - SearchDeals
+- SearchCountryCode
+- SearchLanguage
+- SearchLanguageFilter
+- SearchActionLinks
+- SearchParam(name, value), for parameters without a dedicated option
+
+Search options that are not part of this package can claim their own exclusive
+slot through RegisterSearchOption(...).
 
 This method if searching is slightly slower, but the resulting code is
 more easily maintainable and will check for the possibility of multiple
 defined search options.
+Validate(...) reports every problem in a list of options at once, including
+combinations of options Yelp does not accept.
+The same options can be composed fluently through NewSearch(), for example
+NewSearch().Near("Delft").Terms("bar").Radius(2000).Search(client).
+Searches typed as text, such as `sushi near:"Delft" radius:2km sort:rating`,
+are converted into options by ParseSearch(...) and back by FormatSearch(...).
+
+Regions containing more businesses than Yelp allows to be retrieved by a single
+search can be searched exhaustively through Client.Sweep(...), which divides the
+region into tiles and combines the results.
+
+Every request can be observed, modified or short-circuited by adding Middleware
+to the client through Client.Use(...), for example for logging or metrics.
+
+The load of a single client can be spread over multiple keys by creating it
+through NewFromCredentialPool(...). Keys that exhausted their quota are
+quarantined and failed requests are signed again with the next key.
 
 The query will still have to be checked for possible errors. In case the error
 originated from within the Yelp API this error can be displayed.
@@ -53,8 +80,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 )
 
 //The responseError struct is used when the data request was not successfully
@@ -84,17 +113,25 @@ type responseErrorContainer struct {
 //components implementing the SearchQuerier interface. This will be
 //computationally more intensive but safer.
 type Client struct {
-	url    string
-	signer oauth
+	url        string
+	signer     Signer
+	bearer     string
+	middleware []Middleware
+	metrics    *metrics
+	logger     *slog.Logger
+	pool       *CredentialPool
+	defaults   []SearchQuerier
 }
 
 //New will create a new client from the provided arguments.
 func New(URL, consumerKey, consumerSecret, token, tokenSecret string) (c *Client) {
 	c = &Client{}
 	c.url = URL
+	c.metrics = newMetrics()
 	c.signer.ConsumerKey = consumerKey
+	c.signer.ConsumerSecret = consumerSecret
 	c.signer.Token = token
-	c.signer.SetHashKey(consumerSecret, tokenSecret)
+	c.signer.TokenSecret = tokenSecret
 
 	return
 }
@@ -105,8 +142,9 @@ func New(URL, consumerKey, consumerSecret, token, tokenSecret string) (c *Client
 //detailing the contents of the error message. If the default error message is
 //not on the page, then this function will attempt to unmarshal the page
 //assuming it contains businesses. If this assumption is invalid and/or the
-//data is incorrect, this function will return an error
-func (c Client) validateResponse(response []byte) (businesses *Businesses, err error) {
+//data is incorrect, this function will return an error. The ID of the error
+//returned by Yelp is returned as well
+func (c Client) validateResponse(response []byte) (businesses *Businesses, id string, err error) {
 	//peek ahead in the reponse to see if the first found text is 'error'
 	found := false
 	var text string
@@ -125,7 +163,7 @@ func (c Client) validateResponse(response []byte) (businesses *Businesses, err e
 
 			if !found {
 				//did not find a second bracket
-				return nil, Error{ErrorTypeInvalidYelpResponse, "Client", "Could not find a matching closing '\"' bracket while searching for the first JSON entry"}
+				return nil, "", Error{ErrorTypeInvalidYelpResponse, "Client", "Could not find a matching closing '\"' bracket while searching for the first JSON entry"}
 			}
 
 			break
@@ -134,7 +172,7 @@ func (c Client) validateResponse(response []byte) (businesses *Businesses, err e
 
 	if !found {
 		//did not find an opening bracket
-		return nil, Error{ErrorTypeInvalidYelpResponse, "Client", "Could not find an opening '\"' bracket while search for the first JSON entry"}
+		return nil, "", Error{ErrorTypeInvalidYelpResponse, "Client", "Could not find an opening '\"' bracket while search for the first JSON entry"}
 	}
 
 	//check if the returned data contained an error
@@ -145,11 +183,11 @@ func (c Client) validateResponse(response []byte) (businesses *Businesses, err e
 
 		if e != nil {
 			//Unmarshaling into the error structure also yielded problems
-			return nil, Error{ErrorTypeInvalidYelpResponse, "Client", "Error retrieved from Yelp, could not unmarshal it"}
+			return nil, "", Error{ErrorTypeInvalidYelpResponse, "Client", "Error retrieved from Yelp, could not unmarshal it"}
 		}
 
 		//Return error information
-		return nil, Error{ErrorTypeInvalidYelpResponse, "Client", fmt.Sprintf("Retrieved error from Yelp:\n\tText:%v\n\tID:%v\n\tDescription:%v",
+		return nil, yelpError.Error.ID, Error{ErrorTypeInvalidYelpResponse, "Client", fmt.Sprintf("Retrieved error from Yelp:\n\tText:%v\n\tID:%v\n\tDescription:%v",
 			yelpError.Error.Text, yelpError.Error.ID, yelpError.Error.Description)}
 	}
 
@@ -161,44 +199,211 @@ func (c Client) validateResponse(response []byte) (businesses *Businesses, err e
 }
 
 //SearchQuery allows performing a search on the Yelp API by specifying the
-//query elements manually. The SearchQuery object is passed to this function by
-//copy such that the original query will not be altered after this function is
-//completed (succesfully or otherwise). The reason being that OAuth query
-//elements have to be added to the query.
+//query elements manually. The query is never altered by this function, even
+//though OAuth query elements have to be added to it, such that the same query
+//may be used by multiple goroutines at the same time.
 func (c Client) SearchQuery(q SearchQuery) (*Businesses, error) {
-	//The Query is intentionally passed to the function by value such that the
-	//original query is not changed when the oauth-elements get added to the
-	//query
-	qp := &q
+	request, err := c.newRequest(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//pass the request through the middleware chain
+	response, err := c.handler()(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response == nil || response.Businesses == nil {
+		return nil, Error{ErrorTypeInvalidYelpResponse, "Client", "No businesses were returned by the request handler"}
+	}
+
+	return response.Businesses, nil
+}
+
+//newRequest creates the request for the provided query, authenticated with the
+//credentials of the client or with the next key of its credential pool
+func (c Client) newRequest(q SearchQuery) (*Request, error) {
+	if c.pool == nil {
+		return c.authenticate(q)
+	}
+
+	key, err := c.pool.acquire(nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := c.withCredentials(key.credentials).authenticate(q)
 
-	//sign the current query
-	err := c.signer.Sign("GET", c.url, qp)
+	if err != nil {
+		return nil, err
+	}
+
+	request.key = key
+	return request, nil
+}
+
+//withCredentials returns a copy of the client that authenticates with the
+//provided credentials
+func (c Client) withCredentials(credentials Credentials) Client {
+	c.signer.ConsumerKey = credentials.ConsumerKey
+	c.signer.ConsumerSecret = credentials.ConsumerSecret
+	c.signer.Token = credentials.Token
+	c.signer.TokenSecret = credentials.TokenSecret
+	c.bearer = credentials.BearerToken
+
+	return c
+}
+
+//authenticate creates the request for the provided query, authenticated either
+//through a bearer token or by signing the query. The oauth-elements are added
+//to a clone of the query, as a copy of the query still shares its elements with
+//the original query of the caller. The request receives its own copy of the
+//unsigned query
+func (c Client) authenticate(q SearchQuery) (*Request, error) {
+	request := &Request{Query: q.clone()}
+	var err error
+
+	if c.bearer != "" {
+		//no signing is needed, the token is sent in the headers
+		request.HTTP, err = http.NewRequest("GET", strings.Join([]string{c.url, q.encode(EncodingRFC3986)}, "?"), nil)
+
+		if err != nil {
+			return nil, Error{ErrorTypeHTTPFailure, "Client", "Failed to create HTTP request"}
+		}
+
+		request.HTTP.Header.Set("Authorization", "Bearer "+c.bearer)
+		return request, nil
+	}
+
+	//sign a clone of the current query
+	signed := q.clone()
+	qp := &signed
+	request.Signature, err = c.signer.sign("GET", c.url, qp)
 
 	if err != nil {
 		return nil, Error{ErrorTypeOAuthFailure, "Client", "Failed to sign request"}
 	}
 
-	//create the URL from which to request data
-	data, err := http.Get(strings.Join([]string{c.url, qp.String()}, "?"))
-	defer data.Body.Close()
+	//create the request for the URL from which to request data
+	request.HTTP, err = http.NewRequest("GET", strings.Join([]string{c.url, qp.encode(c.signer.Encoding)}, "?"), nil)
+
+	if err != nil {
+		return nil, Error{ErrorTypeHTTPFailure, "Client", "Failed to create HTTP request"}
+	}
+
+	return request, nil
+}
+
+//send is the final Handler of the middleware chain. It performs the HTTP
+//request, reads the body of the response and validates it. The returned
+//Response is non-nil whenever Yelp responded, even if an error is returned.
+func (c Client) send(r *Request) (*Response, error) {
+	start := time.Now()
+	data, err := http.DefaultClient.Do(r.HTTP)
 
 	if err != nil {
-		return nil, Error{ErrorTypeHTTPFailure, "Client", "Failed to perform HTTP request"}
+		return nil, Error{ErrorTypeHTTPFailure, "Client", "Failed to perform HTTP request: " + err.Error()}
 	}
 
+	defer data.Body.Close()
+
 	//read the body of the html page
 	body, err := ioutil.ReadAll(data.Body)
+	response := &Response{StatusCode: data.StatusCode, Header: data.Header, Latency: time.Since(start)}
 
 	if err != nil {
-		return nil, Error{ErrorTypeReadFailure, "Client", "Failed to read entire HTML body"}
+		return response, Error{ErrorTypeReadFailure, "Client", "Failed to read entire HTML body"}
 	}
 
 	//validate the response and return businesses and possible error
-	return c.validateResponse(body)
+	response.Businesses, response.ErrorID, err = c.validateResponse(body)
+	return response, err
+}
+
+//SetEncoding sets the manner in which query elements are percent encoded when
+//requests are signed and sent. By default EncodingRFC3986 is used.
+func (c *Client) SetEncoding(e Encoding) {
+	c.signer.Encoding = e
+}
+
+//SetClock sets the clock from which the timestamp of every signed request is
+//taken. When the clock is nil the actual time is used, which is the default.
+func (c *Client) SetClock(clock func() time.Time) {
+	c.signer.Clock = clock
+}
+
+//SetNonceSource sets the source of the nonce of every signed request. When the
+//source is nil a cryptographically secure random nonce is used, which is the
+//default.
+func (c *Client) SetNonceSource(source NonceSource) {
+	c.signer.Nonce = source
+}
+
+//DebugSignature signs the provided query in the same manner SearchQuery(...)
+//would, without performing the request, and returns every intermediate result
+//of the signing process. This can be used to diagnose 'Invalid signature'
+//errors returned by Yelp. Note that every signature uses a new nonce and
+//timestamp. The signature of a request that was actually performed is
+//available to Middleware through Request.Signature. When the client uses a
+//credential pool, the query is signed with the key the next request would use,
+//without affecting which key that request uses.
+func (c Client) DebugSignature(q SearchQuery) (SignatureDebug, error) {
+	if c.pool != nil {
+		key, err := c.pool.peek()
+
+		if err != nil {
+			return SignatureDebug{}, err
+		}
+
+		c = c.withCredentials(key.credentials)
+	}
+
+	qp := q.clone()
+	debug, err := c.signer.sign("GET", c.url, &qp)
+
+	if err != nil {
+		return debug, Error{ErrorTypeOAuthFailure, "Client", "Failed to sign request"}
+	}
+
+	return debug, nil
+}
+
+//SetDefaults sets the options that are added to every search performed through
+//SearchOptions(...). An option provided to SearchOptions(...) overrides the
+//default option of the same kind, e.g. a SearchCoordinates overrides a default
+//SearchLocation. An error is returned when the defaults are invalid or repeat
+//an option, in which case the defaults are not changed.
+func (c *Client) SetDefaults(options ...SearchQuerier) error {
+	var qp SearchQuery
+
+	for _, v := range options {
+		if err := v.Query(&qp); err != nil {
+			return err
+		}
+	}
+
+	c.defaults = append([]SearchQuerier(nil), options...)
+	return nil
+}
+
+//withoutDefaults returns a copy of the client without default options, for
+//searches that set the location, limit and offset themselves
+func (c Client) withoutDefaults() Client {
+	c.defaults = nil
+	return c
 }
 
 //SearchOptions allows performing a search using the Yelp API by options
-//implementing the SearchQuerier interface.
+//implementing the SearchQuerier interface. The default options of the client
+//are added for every kind of option that is not provided, unless the default
+//conflicts with the provided options (e.g. a default SearchRadius is dropped
+//when SearchBounds are provided). The country code and language are overridden
+//together, as a default of one may not be supported in combination with the
+//other.
 func (c Client) SearchOptions(options ...SearchQuerier) (*Businesses, error) {
 	//This version will create a query from the provided options using the
 	//SearchQuerier interface
@@ -213,5 +418,36 @@ func (c Client) SearchOptions(options ...SearchQuerier) (*Businesses, error) {
 		}
 	}
 
+	if len(c.defaults) == 0 {
+		return c.SearchQuery(qp)
+	}
+
+	overridden := qp.mask
+
+	if overridden&(searchBitMaskCountryCode|searchBitMaskLanguage) != 0 {
+		overridden |= searchBitMaskCountryCode | searchBitMaskLanguage
+	}
+
+	//add the defaults that were not overridden. Every default is valid by
+	//itself, so a default that fails in combination with the options is
+	//overridden by them. A default may partially be added before it fails, so
+	//it is added to a clone
+	conflicts := len(qp.validate())
+
+	for _, v := range c.defaults {
+		next := qp.clone()
+
+		if err := v.Query(&next); err != nil || (next.mask&^qp.mask)&overridden != 0 {
+			continue
+		}
+
+		//drop defaults that violate a rule in combination with the options
+		if len(next.validate()) > conflicts {
+			continue
+		}
+
+		qp = next
+	}
+
 	return c.SearchQuery(qp)
 }
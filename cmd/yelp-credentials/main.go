@@ -0,0 +1,186 @@
+//Command yelp-credentials manages the encrypted credential store used by
+//yelp.NewFromProfile(...). Usage:
+//
+//	yelp-credentials [-store path] create
+//	yelp-credentials [-store path] list
+//	yelp-credentials [-store path] get [profile]
+//	yelp-credentials [-store path] set [profile]
+//	yelp-credentials [-store path] import [profile]
+//	yelp-credentials [-store path] delete profile
+//	yelp-credentials [-store path] rotate
+//
+//The passphrase is read from the YELP_CREDENTIALS_PASSPHRASE environment
+//variable, or otherwise from the first line of the standard input. The rotate
+//command reads the new passphrase from YELP_CREDENTIALS_NEW_PASSPHRASE, or from
+//the next line of the standard input. Passphrases are not read from a terminal,
+//as the standard library can not disable the terminal echo and the passphrase
+//would end up on screen and in the scrollback. Use the environment variables or
+//pipe the passphrases in (e.g. from a password manager) instead. The set command stores the credentials
+//found in the YELP_CONSUMER_KEY, YELP_CONSUMER_SECRET, YELP_TOKEN,
+//YELP_TOKEN_SECRET and YELP_BEARER_TOKEN environment variables, such that no
+//secrets end up in the shell history. The import command copies a profile from
+//the plaintext credentials file into the store. The get command prints a
+//profile in the format of the plaintext credentials file.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MaxHenger/yelp"
+)
+
+//envNewPassphrase is the environment variable containing the new passphrase
+//used by the rotate command
+const envNewPassphrase = "YELP_CREDENTIALS_NEW_PASSPHRASE"
+
+var stdin = bufio.NewReader(os.Stdin)
+
+func main() {
+	defaultPath, _ := yelp.DefaultCredentialStorePath()
+	path := flag.String("store", defaultPath, "path of the encrypted credential store")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 || *path == "" {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := run(*path, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "yelp-credentials:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: yelp-credentials [-store path] create|list|get|set|import|delete|rotate [profile]")
+	flag.PrintDefaults()
+}
+
+//run executes the provided command on the credential store at the provided path
+func run(path, command string, args []string) error {
+	profile := ""
+
+	if len(args) > 0 {
+		profile = args[0]
+	}
+
+	passphrase, err := readPassphrase(yelp.EnvCredentialStorePassphrase, "Passphrase")
+
+	if err != nil {
+		return err
+	}
+
+	if command == "create" {
+		_, err = yelp.CreateCredentialStore(path, passphrase)
+		return err
+	}
+
+	store, err := yelp.OpenCredentialStore(path, passphrase)
+
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "list":
+		for _, v := range store.Profiles() {
+			fmt.Println(v)
+		}
+
+		return nil
+	case "get":
+		credentials, err := store.Get(profile)
+
+		if err != nil {
+			return err
+		}
+
+		printProfile(profile, credentials)
+		return nil
+	case "set":
+		return store.Put(profile, yelp.Credentials{
+			ConsumerKey:    os.Getenv(yelp.EnvConsumerKey),
+			ConsumerSecret: os.Getenv(yelp.EnvConsumerSecret),
+			Token:          os.Getenv(yelp.EnvToken),
+			TokenSecret:    os.Getenv(yelp.EnvTokenSecret),
+			BearerToken:    os.Getenv(yelp.EnvBearerToken),
+		})
+	case "import":
+		plaintext, err := yelp.DefaultCredentialsPath()
+
+		if err != nil {
+			return err
+		}
+
+		credentials, err := yelp.LoadProfile(plaintext, profile)
+
+		if err != nil {
+			return err
+		}
+
+		return store.Put(profile, credentials)
+	case "delete":
+		if profile == "" {
+			return fmt.Errorf("delete requires a profile name")
+		}
+
+		return store.Delete(profile)
+	case "rotate":
+		next, err := readPassphrase(envNewPassphrase, "New passphrase")
+
+		if err != nil {
+			return err
+		}
+
+		return store.Rotate(next)
+	}
+
+	return fmt.Errorf("unknown command '%s'", command)
+}
+
+//readPassphrase returns the passphrase in the provided environment variable, or
+//otherwise reads it from the next line of the standard input. Reading from a
+//terminal is refused, as the passphrase would be echoed
+func readPassphrase(env, prompt string) (string, error) {
+	if passphrase := os.Getenv(env); passphrase != "" {
+		return passphrase, nil
+	}
+
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return "", fmt.Errorf("%s can not be read from a terminal without echoing it, set %s or pipe it in", strings.ToLower(prompt), env)
+	}
+
+	line, err := stdin.ReadString('\n')
+	line = strings.TrimRight(line, "\r\n")
+
+	if line == "" {
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %v", err)
+		}
+
+		return "", fmt.Errorf("the passphrase may not be empty")
+	}
+
+	return line, nil
+}
+
+//printProfile prints the credentials in the format of the credentials file
+func printProfile(profile string, c yelp.Credentials) {
+	if profile == "" {
+		profile = yelp.DefaultProfile
+	}
+
+	fmt.Printf("[%s]\n", profile)
+
+	for _, v := range [][2]string{{"consumer_key", c.ConsumerKey}, {"consumer_secret", c.ConsumerSecret},
+		{"token", c.Token}, {"token_secret", c.TokenSecret}, {"bearer_token", c.BearerToken}} {
+		if v[1] != "" {
+			fmt.Printf("%s = %s\n", v[0], v[1])
+		}
+	}
+}
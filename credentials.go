@@ -0,0 +1,263 @@
+package yelp
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+//The environment variables read by NewFromEnv(...)
+const (
+	EnvConsumerKey    = "YELP_CONSUMER_KEY"
+	EnvConsumerSecret = "YELP_CONSUMER_SECRET"
+	EnvToken          = "YELP_TOKEN"
+	EnvTokenSecret    = "YELP_TOKEN_SECRET"
+	EnvBearerToken    = "YELP_BEARER_TOKEN"
+)
+
+//DefaultProfile is the name of the profile used when no profile name is
+//provided
+const DefaultProfile = "default"
+
+//The Credentials structure contains the secrets needed to access the Yelp API.
+//Either the consumer key and secret together with the token and token secret
+//have to be provided, which are used to sign requests with OAuth 1.0a, or a
+//bearer token has to be provided, which is sent along with every request.
+type Credentials struct {
+	ConsumerKey    string
+	ConsumerSecret string
+	Token          string
+	TokenSecret    string
+	BearerToken    string
+}
+
+//Validate returns an error when the credentials are incomplete, or when both a
+//bearer token and OAuth credentials are specified.
+func (c Credentials) Validate() error {
+	oauth := []string{c.ConsumerKey, c.ConsumerSecret, c.Token, c.TokenSecret}
+	specified := 0
+
+	for _, v := range oauth {
+		if v != "" {
+			specified++
+		}
+	}
+
+	switch {
+	case c.BearerToken != "" && specified != 0:
+		return Error{ErrorTypeCredentialFailure, "Credentials", "Both a bearer token and OAuth credentials are specified"}
+	case c.BearerToken == "" && specified != len(oauth):
+		return Error{ErrorTypeCredentialFailure, "Credentials",
+			"Incomplete credentials, either a bearer token or the consumer key, consumer secret, token and token secret are required"}
+	}
+
+	return nil
+}
+
+//NewFromCredentials creates a new client from the provided URL and
+//credentials. An error is returned when the credentials are invalid.
+func NewFromCredentials(URL string, credentials Credentials) (*Client, error) {
+	err := credentials.Validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	c := New(URL, credentials.ConsumerKey, credentials.ConsumerSecret, credentials.Token, credentials.TokenSecret)
+	c.bearer = credentials.BearerToken
+
+	return c, nil
+}
+
+//NewFromEnv creates a new client from the provided URL and the credentials in
+//the YELP_CONSUMER_KEY, YELP_CONSUMER_SECRET, YELP_TOKEN and YELP_TOKEN_SECRET
+//environment variables, or in the YELP_BEARER_TOKEN environment variable.
+func NewFromEnv(URL string) (*Client, error) {
+	return NewFromCredentials(URL, Credentials{
+		ConsumerKey:    os.Getenv(EnvConsumerKey),
+		ConsumerSecret: os.Getenv(EnvConsumerSecret),
+		Token:          os.Getenv(EnvToken),
+		TokenSecret:    os.Getenv(EnvTokenSecret),
+		BearerToken:    os.Getenv(EnvBearerToken),
+	})
+}
+
+//NewFromProfile creates a new client from the provided URL and the credentials
+//of the named profile. When the encrypted credential store at
+//DefaultCredentialStorePath() exists, the profile is read from it using the
+//passphrase in the YELP_CREDENTIALS_PASSPHRASE environment variable. Otherwise
+//the profile is read from the credentials file at DefaultCredentialsPath().
+//When the profile name is empty the DefaultProfile is used.
+func NewFromProfile(URL, profile string) (*Client, error) {
+	encrypted, err := DefaultCredentialStorePath()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = os.Stat(encrypted); err == nil {
+		passphrase := os.Getenv(EnvCredentialStorePassphrase)
+
+		if passphrase == "" {
+			return nil, Error{ErrorTypeCredentialFailure, "Credentials",
+				fmt.Sprintf("Encrypted credential store %s requires a passphrase in %s", encrypted, EnvCredentialStorePassphrase)}
+		}
+
+		return NewFromEncryptedProfile(URL, profile, passphrase)
+	}
+
+	path, err := DefaultCredentialsPath()
+
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := LoadProfile(path, profile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFromCredentials(URL, credentials)
+}
+
+//DefaultCredentialsPath returns the path of the default credentials file,
+//which is ~/.config/yelp/credentials on every platform.
+func DefaultCredentialsPath() (string, error) {
+	directory, err := os.UserHomeDir()
+
+	if err != nil {
+		return "", Error{ErrorTypeCredentialFailure, "Credentials", "Could not determine the home directory: " + err.Error()}
+	}
+
+	return filepath.Join(directory, ".config", "yelp", "credentials"), nil
+}
+
+//LoadProfile reads the credentials of the named profile from the credentials
+//file at the provided path. When the profile name is empty the DefaultProfile
+//is used. The file should not be accessible by other users than its owner. It
+//contains one section per profile, with one key-value pair per line:
+//
+//	[default]
+//	consumer_key = ...
+//	consumer_secret = ...
+//	token = ...
+//	token_secret = ...
+//
+//	[fusion]
+//	bearer_token = ...
+//
+//Empty lines and lines starting with '#' or ';' are ignored. A profile or a key
+//within a profile may not be repeated.
+func LoadProfile(path, profile string) (Credentials, error) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return Credentials{}, Error{ErrorTypeCredentialFailure, "Credentials", "Failed to open credentials file: " + err.Error()}
+	}
+
+	defer file.Close()
+
+	//the secrets should not be readable by anyone else
+	info, err := file.Stat()
+
+	if err != nil {
+		return Credentials{}, Error{ErrorTypeCredentialFailure, "Credentials", "Failed to stat credentials file: " + err.Error()}
+	}
+
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return Credentials{}, Error{ErrorTypeCredentialFailure, "Credentials",
+			fmt.Sprintf("Credentials file %s is accessible by other users (mode %v), it should be 0600", path, info.Mode().Perm())}
+	}
+
+	profiles, err := parseProfiles(bufio.NewScanner(file))
+
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	credentials, ok := profiles[profile]
+
+	if !ok {
+		return Credentials{}, Error{ErrorTypeCredentialFailure, "Credentials", fmt.Sprintf("Profile '%s' not found in %s", profile, path)}
+	}
+
+	err = credentials.Validate()
+
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	return credentials, nil
+}
+
+//parseProfiles reads all profiles from a credentials file
+func parseProfiles(scanner *bufio.Scanner) (map[string]Credentials, error) {
+	profiles := make(map[string]Credentials)
+	profile := ""
+	var keys map[string]bool
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case text == "" || text[0] == '#' || text[0] == ';':
+			continue
+		case text[0] == '[' && text[len(text)-1] == ']':
+			profile = strings.TrimSpace(text[1 : len(text)-1])
+
+			if _, ok := profiles[profile]; ok {
+				return nil, Error{ErrorTypeCredentialFailure, "Credentials", fmt.Sprintf("Profile '%s' repeated on line %d in credentials file", profile, line)}
+			}
+
+			profiles[profile] = Credentials{}
+			keys = make(map[string]bool)
+			continue
+		}
+
+		key, value, ok := strings.Cut(text, "=")
+
+		if !ok || profile == "" {
+			return nil, Error{ErrorTypeCredentialFailure, "Credentials", fmt.Sprintf("Invalid line %d in credentials file", line)}
+		}
+
+		key = strings.TrimSpace(key)
+
+		if keys[key] {
+			return nil, Error{ErrorTypeCredentialFailure, "Credentials", fmt.Sprintf("Key '%s' repeated on line %d in credentials file", key, line)}
+		}
+
+		keys[key] = true
+		credentials := profiles[profile]
+
+		switch key {
+		case "consumer_key":
+			credentials.ConsumerKey = strings.TrimSpace(value)
+		case "consumer_secret":
+			credentials.ConsumerSecret = strings.TrimSpace(value)
+		case "token":
+			credentials.Token = strings.TrimSpace(value)
+		case "token_secret":
+			credentials.TokenSecret = strings.TrimSpace(value)
+		case "bearer_token":
+			credentials.BearerToken = strings.TrimSpace(value)
+		default:
+			return nil, Error{ErrorTypeCredentialFailure, "Credentials", fmt.Sprintf("Unknown key '%s' on line %d in credentials file", key, line)}
+		}
+
+		profiles[profile] = credentials
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, Error{ErrorTypeReadFailure, "Credentials", "Failed to read credentials file: " + err.Error()}
+	}
+
+	return profiles, nil
+}
@@ -0,0 +1,116 @@
+package yelp
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testCredentialsFile = `# test credentials
+[default]
+consumer_key = key
+consumer_secret = secret
+token = token
+token_secret = tokensecret
+
+[fusion]
+bearer_token = bearer
+
+[incomplete]
+consumer_key = key
+`
+
+func TestLoadProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(path, []byte(testCredentialsFile), 0600); err != nil {
+		t.Fatalf("Failed to write credentials file: %v", err)
+	}
+
+	credentials, err := LoadProfile(path, "")
+	if err != nil || credentials != (Credentials{"key", "secret", "token", "tokensecret", ""}) {
+		t.Errorf("Expected the default profile to be loaded, got '%v', '%v'", credentials, err)
+	}
+
+	credentials, err = LoadProfile(path, "fusion")
+	if err != nil || credentials.BearerToken != "bearer" {
+		t.Errorf("Expected the fusion profile to be loaded, got '%v', '%v'", credentials, err)
+	}
+
+	for _, v := range []string{"incomplete", "nonexisting"} {
+		if _, err = LoadProfile(path, v); err == nil {
+			t.Errorf("Expected loading profile '%s' to fail", v)
+		}
+	}
+
+	//repeated profiles and keys are rejected with their line
+	for _, v := range []struct {
+		file string
+		line string
+	}{
+		{"[default]\ntoken = a\n[fusion]\nbearer_token = b\n[default]\n", "line 5"},
+		{"[default]\ntoken = a\n\ntoken = b\n", "line 4"},
+	} {
+		repeated := filepath.Join(t.TempDir(), "credentials")
+		os.WriteFile(repeated, []byte(v.file), 0600)
+
+		if _, err = LoadProfile(repeated, "fusion"); err == nil || !strings.Contains(err.Error(), v.line) {
+			t.Errorf("Expected the repetition to be reported on %s, got '%v'", v.line, err)
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		os.Chmod(path, 0644)
+
+		if _, err = LoadProfile(path, ""); err == nil {
+			t.Errorf("Expected loading a world-readable credentials file to fail")
+		}
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv(EnvConsumerKey, "key")
+	t.Setenv(EnvConsumerSecret, "secret")
+	t.Setenv(EnvToken, "token")
+	t.Setenv(EnvTokenSecret, "")
+	t.Setenv(EnvBearerToken, "")
+
+	if _, err := NewFromEnv("http://api.yelp.com/v2/search"); err == nil {
+		t.Errorf("Expected incomplete environment credentials to fail")
+	}
+
+	t.Setenv(EnvTokenSecret, "tokensecret")
+
+	if _, err := NewFromEnv("http://api.yelp.com/v2/search"); err != nil {
+		t.Errorf("Expected complete environment credentials to succeed: %v", err)
+	}
+}
+
+func TestBearerClient(t *testing.T) {
+	if _, err := NewFromCredentials("https://api.yelp.com/v3/businesses/search", Credentials{BearerToken: "bearer"}); err != nil {
+		t.Fatalf("Expected creating a bearer client to succeed: %v", err)
+	}
+
+	c := stubClient(t, func(r *Request) (*Response, error) {
+		if r.HTTP.Header.Get("Authorization") != "Bearer bearer" || r.HTTP.URL.Query().Get("oauth_signature") != "" {
+			t.Errorf("Expected a bearer request without signature, got '%v'", r.HTTP.URL)
+		}
+
+		return &Response{Businesses: &Businesses{}}, nil
+	}).withCredentials(Credentials{BearerToken: "bearer"})
+
+	if _, err := c.SearchOptions(SearchLocation("Delft")); err != nil {
+		t.Errorf("Expected the search to succeed: %v", err)
+	}
+}
+
+func TestDefaultCredentialsPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	if path, err := DefaultCredentialsPath(); err != nil || path != filepath.Join(home, ".config", "yelp", "credentials") {
+		t.Errorf("Expected the credentials file to be in the home directory, got '%s', '%v'", path, err)
+	}
+}
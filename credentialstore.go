@@ -0,0 +1,355 @@
+package yelp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+//The credential store file format. The key used to encrypt the profiles is
+//derived from the passphrase using PBKDF2 with HMAC-SHA256, and the profiles
+//are encrypted with AES-256 in GCM mode
+const (
+	credentialStoreVersion = 1
+	credentialStoreKDF     = "pbkdf2-sha256"
+	credentialStoreCipher  = "aes-256-gcm"
+	credentialStoreSaltLen = 16
+	credentialStoreKeyLen  = 32
+)
+
+//credentialStoreIterations is the number of PBKDF2 iterations used when a
+//credential store is encrypted
+var credentialStoreIterations = 600000
+
+//credentialStoreMaxIterations is the largest number of PBKDF2 iterations
+//accepted when a credential store is opened. The iterations are read from the
+//file before it is authenticated, so a tampered file could otherwise make the
+//key derivation run for hours
+const credentialStoreMaxIterations = 10000000
+
+//EnvCredentialStorePassphrase is the environment variable containing the
+//passphrase used by NewFromProfile(...) to open an encrypted credential store
+const EnvCredentialStorePassphrase = "YELP_CREDENTIALS_PASSPHRASE"
+
+//The credentialStoreFile structure is the JSON representation of an encrypted
+//credential store on disk. All parameters needed to derive the key are stored
+//alongside the ciphertext and are authenticated as additional data
+type credentialStoreFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Cipher     string `json:"cipher"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+//additionalData returns the parameters of the file that are authenticated
+//together with the ciphertext
+func (f credentialStoreFile) additionalData() []byte {
+	return []byte(strconv.Itoa(f.Version) + "|" + f.KDF + "|" + strconv.Itoa(f.Iterations) + "|" + f.Cipher + "|" + string(f.Salt))
+}
+
+//aead creates the authenticated cipher for the file from the passphrase
+func (f credentialStoreFile) aead(passphrase string) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, f.Salt, f.Iterations, credentialStoreKeyLen)
+
+	if err != nil {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore", "Failed to derive key: " + err.Error()}
+	}
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore", "Failed to create cipher: " + err.Error()}
+	}
+
+	return cipher.NewGCM(block)
+}
+
+//The CredentialStore structure is an encrypted credentials file containing
+//multiple named profiles. The file is encrypted with a key derived from a
+//passphrase, such that no plaintext secrets are stored on disk. A store is
+//created through CreateCredentialStore(...) or opened through
+//OpenCredentialStore(...). Every modification is immediately written to disk.
+type CredentialStore struct {
+	path       string
+	passphrase string
+	profiles   map[string]Credentials
+}
+
+//CreateCredentialStore creates a new and empty encrypted credential store at
+//the provided path, protected by the provided passphrase. An error is returned
+//when the file already exists.
+func CreateCredentialStore(path, passphrase string) (*CredentialStore, error) {
+	if passphrase == "" {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore", "The passphrase may not be empty"}
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore", "Credential store already exists: " + path}
+	}
+
+	s := &CredentialStore{path, passphrase, make(map[string]Credentials)}
+	return s, s.save()
+}
+
+//OpenCredentialStore opens and decrypts the credential store at the provided
+//path using the provided passphrase. An error is returned when the passphrase
+//is wrong or the file has been tampered with.
+func OpenCredentialStore(path, passphrase string) (*CredentialStore, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore", "Failed to read credential store: " + err.Error()}
+	}
+
+	var file credentialStoreFile
+	err = json.Unmarshal(data, &file)
+
+	if err != nil {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore", "Failed to decode credential store: " + err.Error()}
+	}
+
+	if file.Version != credentialStoreVersion || file.KDF != credentialStoreKDF || file.Cipher != credentialStoreCipher {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore",
+			fmt.Sprintf("Unsupported credential store format: version %d, %s, %s", file.Version, file.KDF, file.Cipher)}
+	}
+
+	if file.Iterations <= 0 || file.Iterations > credentialStoreMaxIterations {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore",
+			fmt.Sprintf("Invalid number of key derivation iterations: %d", file.Iterations)}
+	}
+
+	aead, err := file.aead(passphrase)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore", "Invalid nonce in credential store"}
+	}
+
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, file.additionalData())
+
+	if err != nil {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore", "Failed to decrypt credential store, wrong passphrase or corrupted file"}
+	}
+
+	s := &CredentialStore{path, passphrase, make(map[string]Credentials)}
+	err = json.Unmarshal(plaintext, &s.profiles)
+
+	if err != nil {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialStore", "Failed to decode decrypted profiles"}
+	}
+
+	return s, nil
+}
+
+//Profiles returns the sorted names of all profiles in the store.
+func (s *CredentialStore) Profiles() []string {
+	names := make([]string, 0, len(s.profiles))
+
+	for k := range s.profiles {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+//Get returns the credentials of the named profile. When the profile name is
+//empty the DefaultProfile is used.
+func (s *CredentialStore) Get(profile string) (Credentials, error) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	credentials, ok := s.profiles[profile]
+
+	if !ok {
+		return Credentials{}, Error{ErrorTypeCredentialFailure, "CredentialStore", fmt.Sprintf("Profile '%s' not found in %s", profile, s.path)}
+	}
+
+	return credentials, nil
+}
+
+//Put validates the provided credentials and stores them as the named profile,
+//replacing the credentials of an existing profile. This can be used to rotate
+//the credentials of a profile. When the profile name is empty the
+//DefaultProfile is used.
+func (s *CredentialStore) Put(profile string, credentials Credentials) error {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	err := credentials.Validate()
+
+	if err != nil {
+		return err
+	}
+
+	previous, existed := s.profiles[profile]
+	s.profiles[profile] = credentials
+	err = s.save()
+
+	//keep the store consistent with the file when saving failed
+	if err != nil {
+		if existed {
+			s.profiles[profile] = previous
+		} else {
+			delete(s.profiles, profile)
+		}
+	}
+
+	return err
+}
+
+//Delete removes the named profile from the store.
+func (s *CredentialStore) Delete(profile string) error {
+	previous, ok := s.profiles[profile]
+
+	if !ok {
+		return Error{ErrorTypeCredentialFailure, "CredentialStore", fmt.Sprintf("Profile '%s' not found in %s", profile, s.path)}
+	}
+
+	delete(s.profiles, profile)
+	err := s.save()
+
+	if err != nil {
+		s.profiles[profile] = previous
+	}
+
+	return err
+}
+
+//Rotate re-encrypts the store with a new passphrase, using a new salt.
+func (s *CredentialStore) Rotate(passphrase string) error {
+	if passphrase == "" {
+		return Error{ErrorTypeCredentialFailure, "CredentialStore", "The passphrase may not be empty"}
+	}
+
+	previous := s.passphrase
+	s.passphrase = passphrase
+	err := s.save()
+
+	if err != nil {
+		s.passphrase = previous
+	}
+
+	return err
+}
+
+//save encrypts the store with a new salt and nonce and atomically replaces the
+//file on disk, which is only accessible by its owner
+func (s *CredentialStore) save() error {
+	plaintext, err := json.Marshal(s.profiles)
+
+	if err != nil {
+		return Error{ErrorTypeCredentialFailure, "CredentialStore", "Failed to encode profiles"}
+	}
+
+	file := credentialStoreFile{Version: credentialStoreVersion, KDF: credentialStoreKDF,
+		Iterations: credentialStoreIterations, Cipher: credentialStoreCipher, Salt: make([]byte, credentialStoreSaltLen)}
+	rand.Read(file.Salt)
+
+	aead, err := file.aead(s.passphrase)
+
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, aead.NonceSize())
+	rand.Read(file.Nonce)
+	file.Ciphertext = aead.Seal(nil, file.Nonce, plaintext, file.additionalData())
+
+	data, err := json.MarshalIndent(file, "", "\t")
+
+	if err != nil {
+		return Error{ErrorTypeCredentialFailure, "CredentialStore", "Failed to encode credential store"}
+	}
+
+	//write to a temporary file first, such that the store is never corrupted
+	temporary, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+
+	if err != nil {
+		return Error{ErrorTypeWriteFailure, "CredentialStore", "Failed to create credential store: " + err.Error()}
+	}
+
+	defer os.Remove(temporary.Name())
+
+	_, err = temporary.Write(data)
+
+	if err == nil {
+		err = temporary.Chmod(0600)
+	}
+
+	if closeErr := temporary.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(temporary.Name(), s.path)
+	}
+
+	if err != nil {
+		return Error{ErrorTypeWriteFailure, "CredentialStore", "Failed to write credential store: " + err.Error()}
+	}
+
+	return nil
+}
+
+//DefaultCredentialStorePath returns the path of the default encrypted
+//credential store, which is credentials.enc next to the credentials file at
+//DefaultCredentialsPath().
+func DefaultCredentialStorePath() (string, error) {
+	path, err := DefaultCredentialsPath()
+
+	if err != nil {
+		return "", err
+	}
+
+	return path + ".enc", nil
+}
+
+//LoadEncryptedProfile reads the credentials of the named profile from the
+//encrypted credential store at the provided path, using the provided
+//passphrase. When the profile name is empty the DefaultProfile is used.
+func LoadEncryptedProfile(path, profile, passphrase string) (Credentials, error) {
+	s, err := OpenCredentialStore(path, passphrase)
+
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	return s.Get(profile)
+}
+
+//NewFromEncryptedProfile creates a new client from the provided URL and the
+//credentials of the named profile in the encrypted credential store at
+//DefaultCredentialStorePath(). When the profile name is empty the
+//DefaultProfile is used.
+func NewFromEncryptedProfile(URL, profile, passphrase string) (*Client, error) {
+	path, err := DefaultCredentialStorePath()
+
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := LoadEncryptedProfile(path, profile, passphrase)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFromCredentials(URL, credentials)
+}
@@ -0,0 +1,130 @@
+package yelp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCredentialStore(t *testing.T) {
+	//keep the key derivation fast during the tests
+	iterations := credentialStoreIterations
+	credentialStoreIterations = 1000
+	defer func() { credentialStoreIterations = iterations }()
+
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	store, err := CreateCredentialStore(path, "passphrase")
+	if err != nil {
+		t.Fatalf("Failed to create credential store: %v", err)
+	}
+
+	if _, err = CreateCredentialStore(path, "passphrase"); err == nil {
+		t.Errorf("Expected creating an existing credential store to fail")
+	}
+
+	oauth := Credentials{"key", "secret", "token", "tokensecret", ""}
+	if err = store.Put("", oauth); err != nil {
+		t.Fatalf("Failed to store the default profile: %v", err)
+	}
+
+	if err = store.Put("fusion", Credentials{BearerToken: "bearer"}); err != nil {
+		t.Fatalf("Failed to store the fusion profile: %v", err)
+	}
+
+	if err = store.Put("incomplete", Credentials{ConsumerKey: "key"}); err == nil {
+		t.Errorf("Expected storing incomplete credentials to fail")
+	}
+
+	//no secret may be stored in plaintext
+	data, _ := os.ReadFile(path)
+	for _, v := range []string{"secret", "tokensecret", "bearer"} {
+		if strings.Contains(string(data), v) {
+			t.Errorf("Expected '%s' not to be stored in plaintext", v)
+		}
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0077 != 0 {
+		t.Errorf("Expected the credential store to be private, got mode %v", info.Mode().Perm())
+	}
+
+	if _, err = OpenCredentialStore(path, "wrong"); err == nil {
+		t.Errorf("Expected opening the credential store with a wrong passphrase to fail")
+	}
+
+	credentials, err := LoadEncryptedProfile(path, "", "passphrase")
+	if err != nil || credentials != oauth {
+		t.Errorf("Expected the default profile to be loaded, got '%v', '%v'", credentials, err)
+	}
+
+	//rotate the passphrase and remove a profile
+	if err = store.Rotate("rotated"); err != nil {
+		t.Fatalf("Failed to rotate the passphrase: %v", err)
+	}
+
+	if err = store.Delete("fusion"); err != nil {
+		t.Fatalf("Failed to delete the fusion profile: %v", err)
+	}
+
+	if _, err = OpenCredentialStore(path, "passphrase"); err == nil {
+		t.Errorf("Expected the old passphrase to be rejected after rotating")
+	}
+
+	store, err = OpenCredentialStore(path, "rotated")
+	if err != nil {
+		t.Fatalf("Failed to open the rotated credential store: %v", err)
+	}
+
+	if profiles := store.Profiles(); len(profiles) != 1 || profiles[0] != DefaultProfile {
+		t.Errorf("Expected only the default profile to remain, got '%v'", profiles)
+	}
+
+	//tampering with the header must be detected
+	data, _ = os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), "\"iterations\": 1000", "\"iterations\": 1001", 1)), 0600)
+	if _, err = OpenCredentialStore(path, "rotated"); err == nil {
+		t.Errorf("Expected opening a tampered credential store to fail")
+	}
+
+	//an excessive number of iterations must be rejected before deriving the key
+	os.WriteFile(path, []byte(strings.Replace(string(data), "\"iterations\": 1000", "\"iterations\": 2000000000", 1)), 0600)
+	if _, err = OpenCredentialStore(path, "rotated"); err == nil || !strings.Contains(err.Error(), "iterations") {
+		t.Errorf("Expected an excessive number of iterations to be rejected, got '%v'", err)
+	}
+}
+
+func TestNewFromProfileEncrypted(t *testing.T) {
+	iterations := credentialStoreIterations
+	credentialStoreIterations = 1000
+	defer func() { credentialStoreIterations = iterations }()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	path, err := DefaultCredentialStorePath()
+	if err != nil {
+		t.Fatalf("Failed to determine the credential store path: %v", err)
+	}
+
+	os.MkdirAll(filepath.Dir(path), 0700)
+	store, err := CreateCredentialStore(path, "passphrase")
+	if err != nil {
+		t.Fatalf("Failed to create credential store: %v", err)
+	}
+
+	if err = store.Put("fusion", Credentials{BearerToken: "bearer"}); err != nil {
+		t.Fatalf("Failed to store the fusion profile: %v", err)
+	}
+
+	t.Setenv(EnvCredentialStorePassphrase, "")
+	if _, err = NewFromProfile("", "fusion"); err == nil {
+		t.Errorf("Expected loading an encrypted profile without a passphrase to fail")
+	}
+
+	t.Setenv(EnvCredentialStorePassphrase, "passphrase")
+	client, err := NewFromProfile("", "fusion")
+	if err != nil || client == nil {
+		t.Errorf("Expected the encrypted profile to be loaded, got '%v'", err)
+	}
+}
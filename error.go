@@ -15,6 +15,7 @@ const (
 	ErrorTypeReadFailure
 	ErrorTypeWriteFailure
 	ErrorTypeOAuthFailure
+	ErrorTypeCredentialFailure
 	//Note: If this value starts exceeding 8 error type values, update the
 	//'ErrorType' definition to be larger than a byte
 )
@@ -35,6 +36,8 @@ func (e ErrorType) String() string {
 		return "Write failure"
 	case ErrorTypeOAuthFailure:
 		return "OAuth failure"
+	case ErrorTypeCredentialFailure:
+		return "Credential failure"
 	default:
 		return "Unknown"
 	}
@@ -50,8 +53,9 @@ type Error struct {
 }
 
 func (e Error) Error() string {
-	//performance is not interesting with error printing
+	//performance is not interesting with error printing. Secrets that might be
+	//part of the message (e.g. in a failed URL) are always redacted
 	return "Type: " + e.EType.String() +
 		"\nSource: " + e.source +
-		"\nMessage: " + e.message
+		"\nMessage: " + redact(e.message)
 }
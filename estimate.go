@@ -0,0 +1,135 @@
+package yelp
+
+//searchDailyQuota is the default number of requests Yelp allows a single set
+//of credentials to perform per day
+const searchDailyQuota = 25000
+
+//The Estimate structure contains the expected cost of a crawl, as determined by
+//Client.EstimateSweep(...) or Client.EstimateSearches(...). Requests is the
+//expected number of requests the crawl itself will perform, Probes is the
+//number of requests that were needed to make the estimate. Businesses is the
+//number of businesses that are expected to be retrieved, which may include
+//businesses that will be found more than once.
+type Estimate struct {
+	Requests   int
+	Probes     int
+	Tiles      int
+	Businesses int
+}
+
+//QuotaImpact returns the fraction of the provided daily request quota the
+//estimated crawl will use, including the probes already performed to make the
+//estimate. When the quota is not positive the default Yelp quota of 25000
+//requests per day is used.
+func (e Estimate) QuotaImpact(dailyQuota int) float64 {
+	if dailyQuota <= 0 {
+		dailyQuota = searchDailyQuota
+	}
+
+	return float64(e.Requests+e.Probes) / float64(dailyQuota)
+}
+
+//EstimateSweep determines how many requests Client.Sweep(...) will need to
+//perform the provided area sweep, without retrieving any full pages. It probes
+//the region with searches limited to a single business to read the total number
+//of businesses per tile, subdividing tiles in the same manner the sweep would.
+//Like Client.Sweep(...), the default options of the client are not used.
+func (c Client) EstimateSweep(s AreaSweep) (*Estimate, error) {
+	return s.estimate(c.withoutDefaults().SearchOptions)
+}
+
+//EstimateSearches determines how many requests are needed to retrieve all
+//retrievable businesses of each of the provided searches (e.g. the same search
+//for multiple cities). Each search is probed once with a search limited to a
+//single business. Limit and offset options in the searches are ignored, as are
+//the default options of the client.
+func (c Client) EstimateSearches(searches ...[]SearchQuerier) (*Estimate, error) {
+	return estimateSearches(c.withoutDefaults().SearchOptions, searches)
+}
+
+//estimate performs the estimation of an area sweep using the provided search
+//function
+func (s AreaSweep) estimate(search searchFunc) (*Estimate, error) {
+	s, err := s.validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Estimate{}
+	pageSize := min(searchMaxLimit, s.MaxResults)
+	tiles := []Bounds{s.Bounds.Bounds()}
+
+	for len(tiles) != 0 {
+		tile := tiles[len(tiles)-1]
+		tiles = tiles[:len(tiles)-1]
+
+		probe, err := search(s.tileOptions(tile, 1, 0)...)
+		result.Probes++
+
+		if err != nil {
+			return nil, err
+		}
+
+		//the sweep always requests the first page of a tile
+		if s.split(tile, probe.Total) {
+			tiles = append(tiles, quadrants(tile)...)
+			result.Requests++
+			continue
+		}
+
+		retrievable := min(probe.Total, s.MaxResults)
+		result.Requests += pages(retrievable, pageSize)
+		result.Businesses += retrievable
+		result.Tiles++
+	}
+
+	return result, nil
+}
+
+//estimateSearches performs the estimation of a list of searches using the
+//provided search function
+func estimateSearches(search searchFunc, searches [][]SearchQuerier) (*Estimate, error) {
+	result := &Estimate{}
+
+	for _, v := range searches {
+		//replace the limit and offset of the search by the probe limit. They are
+		//recognized by the option they set, as they may also be set through
+		//SearchParam(...). Invalid options are kept such that the probe fails
+		options := make([]SearchQuerier, 0, len(v)+1)
+
+		for _, w := range v {
+			var q SearchQuery
+
+			if w.Query(&q) == nil && q.mask&(searchBitMaskLimit|searchBitMaskOffset) != 0 {
+				continue
+			}
+
+			options = append(options, w)
+		}
+
+		probe, err := search(append(options, SearchLimit(1))...)
+		result.Probes++
+
+		if err != nil {
+			return nil, err
+		}
+
+		retrievable := min(probe.Total, searchMaxResults)
+		result.Requests += pages(retrievable, searchMaxLimit)
+		result.Businesses += retrievable
+		result.Tiles++
+	}
+
+	return result, nil
+}
+
+//pages returns the number of pages of the provided size needed to retrieve the
+//provided number of businesses. At least a single page is always requested
+func pages(businesses, pageSize int) int {
+	if businesses <= pageSize {
+		return 1
+	}
+
+	return (businesses + pageSize - 1) / pageSize
+}
@@ -0,0 +1,210 @@
+package yelp
+
+import (
+	"sort"
+	"strings"
+)
+
+//A BusinessPredicate decides whether a business should be kept when filtering
+//the businesses returned by Yelp through Businesses.Filter(...). Predicates can
+//be composed using the And(...), Or(...) and Not(...) functions.
+type BusinessPredicate func(*Business) bool
+
+//MinRating returns a predicate keeping businesses rated at least the provided
+//rating.
+func MinRating(rating float64) BusinessPredicate {
+	return func(b *Business) bool {
+		return b.Rating >= rating
+	}
+}
+
+//MinReviewCount returns a predicate keeping businesses with at least the
+//provided number of reviews.
+func MinReviewCount(count int) BusinessPredicate {
+	return func(b *Business) bool {
+		return b.ReviewCount >= count
+	}
+}
+
+//MaxDistance returns a predicate keeping businesses that are at most the
+//provided distance in meters away from the searched location.
+func MaxDistance(meters float64) BusinessPredicate {
+	return func(b *Business) bool {
+		return b.Distance <= meters
+	}
+}
+
+//OpenOnly is a predicate keeping businesses that are not permanently closed.
+func OpenOnly(b *Business) bool {
+	return !b.IsClosed
+}
+
+//HasPhone is a predicate keeping businesses with a known phone number.
+func HasPhone(b *Business) bool {
+	return b.Phone != "" || b.DisplayPhone != ""
+}
+
+//InCategory returns a predicate keeping businesses belonging to at least one
+//of the provided categories.
+func InCategory(categories ...SearchCategory) BusinessPredicate {
+	aliases := make([]string, 0, len(categories))
+
+	for _, v := range categories {
+		if v.Valid() {
+			aliases = append(aliases, v.String())
+		}
+	}
+
+	return InCategoryAlias(aliases...)
+}
+
+//InCategoryAlias returns a predicate keeping businesses belonging to at least
+//one of the provided categories, specified by their Yelp alias. This allows
+//filtering on categories that have no SearchCategory value.
+func InCategoryAlias(aliases ...string) BusinessPredicate {
+	return func(b *Business) bool {
+		for _, v := range b.Categories {
+			for _, w := range aliases {
+				if strings.EqualFold(v.Alias, w) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+}
+
+//And returns a predicate keeping businesses for which all provided predicates
+//hold.
+func And(predicates ...BusinessPredicate) BusinessPredicate {
+	return func(b *Business) bool {
+		for _, v := range predicates {
+			if !v(b) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+//Or returns a predicate keeping businesses for which at least one of the
+//provided predicates holds.
+func Or(predicates ...BusinessPredicate) BusinessPredicate {
+	return func(b *Business) bool {
+		for _, v := range predicates {
+			if v(b) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+//Not returns a predicate keeping the businesses the provided predicate
+//rejects.
+func Not(predicate BusinessPredicate) BusinessPredicate {
+	return func(b *Business) bool {
+		return !predicate(b)
+	}
+}
+
+//Filter returns new Businesses containing only the businesses for which all
+//provided predicates hold. The Total of the result remains the total number of
+//businesses Yelp reported for the search, use len(Businesses) for the number of
+//kept businesses. The original Businesses are not modified, but the returned
+//Businesses share their Business values with it.
+func (b *Businesses) Filter(predicates ...BusinessPredicate) *Businesses {
+	keep := And(predicates...)
+	result := b.copy(0)
+
+	for _, v := range b.Businesses {
+		if keep(v) {
+			result.Businesses = append(result.Businesses, v)
+		}
+	}
+
+	return result
+}
+
+//A BusinessOrder compares two businesses for sorting through
+//Businesses.SortBy(...). It returns a negative value when a should come before
+//b, a positive value when b should come before a and zero when they are equal.
+//All provided orders sort ascending, use Descending(...) to reverse them.
+type BusinessOrder func(a, b *Business) int
+
+//compareFloat compares two floating point values in the manner of a
+//BusinessOrder
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+//ByRating orders businesses by their rating.
+func ByRating(a, b *Business) int {
+	return compareFloat(a.Rating, b.Rating)
+}
+
+//ByReviewCount orders businesses by their number of reviews.
+func ByReviewCount(a, b *Business) int {
+	return a.ReviewCount - b.ReviewCount
+}
+
+//ByDistance orders businesses by their distance to the searched location.
+func ByDistance(a, b *Business) int {
+	return compareFloat(a.Distance, b.Distance)
+}
+
+//ByName orders businesses alphabetically by their name.
+func ByName(a, b *Business) int {
+	return strings.Compare(a.Name, b.Name)
+}
+
+//Descending reverses the provided order.
+func Descending(order BusinessOrder) BusinessOrder {
+	return func(a, b *Business) int {
+		return order(b, a)
+	}
+}
+
+//SortBy returns new Businesses sorted by the provided orders. Businesses that
+//are equal according to the first order are sorted by the second order, and so
+//on. Businesses equal according to all orders keep their original relative
+//position. The original Businesses are not modified.
+func (b *Businesses) SortBy(orders ...BusinessOrder) *Businesses {
+	result := b.copy(len(b.Businesses))
+	result.Businesses = append(result.Businesses, b.Businesses...)
+
+	sort.SliceStable(result.Businesses, func(i, j int) bool {
+		for _, v := range orders {
+			if c := v(result.Businesses[i], result.Businesses[j]); c != 0 {
+				return c < 0
+			}
+		}
+
+		return false
+	})
+
+	return result
+}
+
+//copy returns a copy of the businesses without any businesses in it, but with
+//room for the provided number of businesses
+func (b *Businesses) copy(capacity int) *Businesses {
+	result := &Businesses{Businesses: make([]*Business, 0, capacity), Total: b.Total}
+
+	if b.Region != nil {
+		region := *b.Region
+		result.Region = &region
+	}
+
+	return result
+}
@@ -0,0 +1,43 @@
+package yelp
+
+import (
+	"testing"
+)
+
+func TestBusinessesFilterSort(t *testing.T) {
+	bars := []BusinessCategory{{"Bars", "bars"}}
+	original := &Businesses{Businesses: []*Business{
+		{ID: "a", Rating: 4.5, ReviewCount: 10, Distance: 300, Categories: bars, Phone: "1"},
+		{ID: "b", Rating: 4.5, ReviewCount: 200, Distance: 900, Categories: bars},
+		{ID: "c", Rating: 5, ReviewCount: 3, Distance: 100, IsClosed: true, Phone: "2"},
+		{ID: "d", Rating: 3, ReviewCount: 500, Distance: 50, Phone: "3"},
+		{ID: "e", Rating: 4.5, ReviewCount: 200, Distance: 400, Categories: bars}},
+		Total: 80}
+
+	filtered := original.Filter(OpenOnly, MinRating(4), Or(HasPhone, InCategory(SearchCategoryBars)))
+	sorted := filtered.SortBy(Descending(ByRating), Descending(ByReviewCount), ByDistance)
+
+	expected := []string{"e", "b", "a"}
+	if len(sorted.Businesses) != len(expected) {
+		t.Fatalf("Expected %d businesses to remain, got %d", len(expected), len(sorted.Businesses))
+	}
+
+	if sorted.Total != 80 {
+		t.Errorf("Expected the total reported by Yelp to be kept, got %d", sorted.Total)
+	}
+
+	for i, v := range expected {
+		if sorted.Businesses[i].ID != v {
+			t.Errorf("Expected business %d to be '%s', got '%s'", i, v, sorted.Businesses[i].ID)
+		}
+	}
+
+	//the original should not have changed
+	if len(original.Businesses) != 5 || original.Businesses[0].ID != "a" || original.Total != 80 {
+		t.Errorf("Expected the original businesses not to be modified")
+	}
+
+	if len(original.Filter(Not(MaxDistance(500)), MinReviewCount(100)).Businesses) != 1 {
+		t.Errorf("Expected a single business further than 500 m with 100 reviews")
+	}
+}
@@ -0,0 +1,227 @@
+package yelp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+//earthRadius is the mean radius of the earth in meters, as used by the
+//haversine distance calculations
+const earthRadius float64 = 6371008.8
+
+//toRadians converts an angle in degrees to radians
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+//toDegrees converts an angle in radians to degrees
+func toDegrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+//normalizeLongitude wraps a longitude in degrees into the range [-180, 180]
+func normalizeLongitude(longitude float64) float64 {
+	if longitude >= -180 && longitude <= 180 {
+		return longitude
+	}
+
+	longitude = math.Mod(longitude+180, 360)
+
+	if longitude < 0 {
+		longitude += 360
+	}
+
+	return longitude - 180
+}
+
+//DistanceTo returns the great-circle distance in meters between the
+//coordinates and the provided coordinates, using the haversine formula.
+func (c Coordinates) DistanceTo(o Coordinates) float64 {
+	lat1, lat2 := toRadians(c.Latitude), toRadians(o.Latitude)
+	dLat := lat2 - lat1
+	dLon := toRadians(o.Longitude - c.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+//BearingTo returns the initial bearing in degrees, in the range [0, 360), when
+//travelling along a great circle from the coordinates to the provided
+//coordinates. A bearing of 0 is north, 90 is east.
+func (c Coordinates) BearingTo(o Coordinates) float64 {
+	lat1, lat2 := toRadians(c.Latitude), toRadians(o.Latitude)
+	dLon := toRadians(o.Longitude - c.Longitude)
+
+	y := math.Sin(dLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+
+	return math.Mod(toDegrees(math.Atan2(y, x))+360, 360)
+}
+
+//BoundsAround returns the smallest bounding box containing all points within
+//the provided radius in meters around the coordinates. When the box would
+//contain one of the poles it spans all longitudes. The returned box can cross
+//the antimeridian.
+func (c Coordinates) BoundsAround(radius float64) Bounds {
+	dLat := toDegrees(radius / earthRadius)
+	south := c.Latitude - dLat
+	north := c.Latitude + dLat
+
+	if south <= -90 || north >= 90 {
+		//the box contains a pole, all longitudes are included
+		return Bounds{Coordinates{math.Max(south, -90), -180}, Coordinates{math.Min(north, 90), 180}}
+	}
+
+	dLon := toDegrees(math.Asin(math.Sin(radius/earthRadius) / math.Cos(toRadians(c.Latitude))))
+
+	if dLon >= 180 {
+		return Bounds{Coordinates{south, -180}, Coordinates{north, 180}}
+	}
+
+	return Bounds{Coordinates{south, normalizeLongitude(c.Longitude - dLon)},
+		Coordinates{north, normalizeLongitude(c.Longitude + dLon)}}
+}
+
+//boundsOf returns the smallest bounding box containing all provided
+//coordinates, which crosses the antimeridian when that results in a narrower
+//box. At least one coordinate must be provided
+func boundsOf(positions []Coordinates) Bounds {
+	b := Bounds{positions[0], positions[0]}
+	longitudes := make([]float64, len(positions))
+
+	for i, v := range positions {
+		b.SouthWest.Latitude = math.Min(b.SouthWest.Latitude, v.Latitude)
+		b.NorthEast.Latitude = math.Max(b.NorthEast.Latitude, v.Latitude)
+		longitudes[i] = normalizeLongitude(v.Longitude)
+	}
+
+	//the box covers everything but the largest gap between the longitudes,
+	//which is the gap across the antimeridian unless another one is larger
+	sort.Float64s(longitudes)
+	last := len(longitudes) - 1
+	b.SouthWest.Longitude, b.NorthEast.Longitude = longitudes[0], longitudes[last]
+	gap := longitudes[0] + 360 - longitudes[last]
+
+	for i := 0; i < last; i++ {
+		if longitudes[i+1]-longitudes[i] > gap {
+			gap = longitudes[i+1] - longitudes[i]
+			b.SouthWest.Longitude, b.NorthEast.Longitude = longitudes[i+1], longitudes[i]
+		}
+	}
+
+	return b
+}
+
+//Within returns true when the coordinates lie within the provided bounds.
+func (c Coordinates) Within(b Bounds) bool {
+	return b.Contains(c)
+}
+
+//The Bounds structure represents a bounding box by its south-west and
+//north-east corners. A box whose south-west longitude is larger than its
+//north-east longitude crosses the antimeridian. Bounds can be converted to and
+//from the SearchBounds search option and a BusinessRegion.
+type Bounds struct {
+	SouthWest Coordinates
+	NorthEast Coordinates
+}
+
+//Validate returns an error when the bounds are not a valid bounding box. This
+//is the case when one of the corners is not a valid coordinate or when the
+//south-west corner does not actually lie south-west of the north-east corner.
+//Boxes crossing the antimeridian are considered to be valid.
+func (b Bounds) Validate() error {
+	return b.validate("Bounds")
+}
+
+//validate performs the validation of Validate(), reporting errors as
+//originating from the provided source
+func (b Bounds) validate(source string) error {
+	if !validLatitudeLongitude(b.SouthWest.Latitude, b.SouthWest.Longitude) {
+		return Error{ErrorTypeInvalidArgumentDefinition, source,
+			fmt.Sprintf("Invalid southwest latitude and/or longitude: %f, %f", b.SouthWest.Latitude, b.SouthWest.Longitude)}
+	}
+
+	if !validLatitudeLongitude(b.NorthEast.Latitude, b.NorthEast.Longitude) {
+		return Error{ErrorTypeInvalidArgumentDefinition, source,
+			fmt.Sprintf("Invalid northeast latitude and/or longitude: %f, %f", b.NorthEast.Latitude, b.NorthEast.Longitude)}
+	}
+
+	if b.SouthWest.Latitude >= b.NorthEast.Latitude {
+		return Error{ErrorTypeInvalidArgumentDefinition, source,
+			fmt.Sprintf("Southwest latitude %f is not south of northeast latitude %f", b.SouthWest.Latitude, b.NorthEast.Latitude)}
+	}
+
+	if b.SouthWest.Longitude == b.NorthEast.Longitude {
+		return Error{ErrorTypeInvalidArgumentDefinition, source,
+			fmt.Sprintf("Southwest and northeast longitude are both %f", b.SouthWest.Longitude)}
+	}
+
+	return nil
+}
+
+//CrossesAntimeridian returns true when the bounding box crosses the 180th
+//meridian.
+func (b Bounds) CrossesAntimeridian() bool {
+	return b.SouthWest.Longitude > b.NorthEast.Longitude
+}
+
+//Span returns the height and width of the bounding box in degrees, taking
+//boxes crossing the antimeridian into account.
+func (b Bounds) Span() Coordinates {
+	width := b.NorthEast.Longitude - b.SouthWest.Longitude
+
+	if b.CrossesAntimeridian() {
+		width += 360
+	}
+
+	return Coordinates{b.NorthEast.Latitude - b.SouthWest.Latitude, width}
+}
+
+//Center returns the coordinates in the middle of the bounding box.
+func (b Bounds) Center() Coordinates {
+	span := b.Span()
+	return Coordinates{b.SouthWest.Latitude + span.Latitude/2,
+		normalizeLongitude(b.SouthWest.Longitude + span.Longitude/2)}
+}
+
+//Contains returns true when the provided coordinates lie within the bounding
+//box, edges included.
+func (b Bounds) Contains(c Coordinates) bool {
+	if c.Latitude < b.SouthWest.Latitude || c.Latitude > b.NorthEast.Latitude {
+		return false
+	}
+
+	if b.CrossesAntimeridian() {
+		return c.Longitude >= b.SouthWest.Longitude || c.Longitude <= b.NorthEast.Longitude
+	}
+
+	return c.Longitude >= b.SouthWest.Longitude && c.Longitude <= b.NorthEast.Longitude
+}
+
+//SearchBounds converts the bounding box into a SearchBounds search option.
+func (b Bounds) SearchBounds() SearchBounds {
+	return SearchBounds{b.SouthWest.Latitude, b.SouthWest.Longitude, b.NorthEast.Latitude, b.NorthEast.Longitude}
+}
+
+//Region converts the bounding box into a BusinessRegion with the center and
+//span of the box.
+func (b Bounds) Region() BusinessRegion {
+	return BusinessRegion{b.Center(), b.Span()}
+}
+
+//Bounds converts the SearchBounds search option into a bounding box.
+func (sb SearchBounds) Bounds() Bounds {
+	return Bounds{Coordinates{sb.SWLatitude, sb.SWLongitude}, Coordinates{sb.NELatitude, sb.NELongitude}}
+}
+
+//Bounds converts the region into the bounding box it describes. The span of the
+//region is the full height and width of the box, centered at the region center.
+func (br BusinessRegion) Bounds() Bounds {
+	return Bounds{
+		Coordinates{br.Center.Latitude - br.Span.Latitude/2, normalizeLongitude(br.Center.Longitude - br.Span.Longitude/2)},
+		Coordinates{br.Center.Latitude + br.Span.Latitude/2, normalizeLongitude(br.Center.Longitude + br.Span.Longitude/2)}}
+}
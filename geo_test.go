@@ -0,0 +1,83 @@
+package yelp
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestCoordinatesDistanceBearing(t *testing.T) {
+	origin := Coordinates{0, 0}
+
+	//one degree along the equator and along a meridian is roughly 111.2 km
+	if d := origin.DistanceTo(Coordinates{0, 1}); math.Abs(d-111195) > 1 {
+		t.Errorf("Expected distance of one degree longitude to be 111195 m, got %f", d)
+	}
+
+	if d := origin.DistanceTo(Coordinates{1, 0}); math.Abs(d-111195) > 1 {
+		t.Errorf("Expected distance of one degree latitude to be 111195 m, got %f", d)
+	}
+
+	bearings := map[Coordinates]float64{{1, 0}: 0, {0, 1}: 90, {-1, 0}: 180, {0, -1}: 270}
+
+	for k, v := range bearings {
+		if b := origin.BearingTo(k); math.Abs(b-v) > 1e-9 {
+			t.Errorf("Expected bearing to '%v' to be %f, got %f", k, v, b)
+		}
+	}
+}
+
+func TestBoundsAntimeridian(t *testing.T) {
+	//a box around Fiji crosses the antimeridian
+	center := Coordinates{-17, 179.9}
+	b := center.BoundsAround(50000)
+
+	if !b.CrossesAntimeridian() {
+		t.Fatalf("Expected bounds '%v' to cross the antimeridian", b)
+	}
+
+	if err := b.Validate(); err != nil {
+		t.Errorf("Expected bounds '%v' to be valid: %v", b, err)
+	}
+
+	if !b.Contains(Coordinates{-17, -179.9}) || !center.Within(b) {
+		t.Errorf("Expected bounds '%v' to contain points on both sides of the antimeridian", b)
+	}
+
+	if b.Contains(Coordinates{-17, 0}) {
+		t.Errorf("Expected bounds '%v' not to contain the prime meridian", b)
+	}
+
+	if c := b.Region().Bounds().Center(); math.Abs(c.Latitude-center.Latitude) > 1e-9 || math.Abs(c.Longitude-center.Longitude) > 1e-9 {
+		t.Errorf("Expected region of '%v' to be centered at '%v', got '%v'", b, center, c)
+	}
+
+	var q SearchQuery
+	if err := b.SearchBounds().Query(&q); err != nil {
+		t.Errorf("Expected search bounds crossing the antimeridian to be valid: %v", err)
+	}
+}
+
+func TestSearchBoundsOrientation(t *testing.T) {
+	invalid := []SearchBounds{{1, 0, 0, 1}, {0, 0, 0, 1}, {0, 1, 1, 1}, {0, 0, 91, 1}}
+
+	for _, v := range invalid {
+		var q SearchQuery
+		if err := v.Query(&q); err == nil {
+			t.Errorf("Expected search bounds '%v' to be invalid", v)
+		}
+	}
+}
+
+func TestRegionSpanUnmarshal(t *testing.T) {
+	var region BusinessRegion
+	data := `{"center": {"latitude": 52, "longitude": 4.3}, "span": {"latitude_delta": 0.2, "longitude_delta": 0.4}}`
+
+	if err := json.Unmarshal([]byte(data), &region); err != nil {
+		t.Fatalf("Expected unmarshalling a region to succeed: %v", err)
+	}
+
+	if region.Span.Latitude != 0.2 || region.Span.Longitude != 0.4 {
+		t.Errorf("Expected span to be read from the delta fields, got '%v'", region.Span)
+	}
+}
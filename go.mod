@@ -0,0 +1,3 @@
+module github.com/MaxHenger/yelp
+
+go 1.24
@@ -0,0 +1,162 @@
+package yelp
+
+import (
+	"fmt"
+	"strconv"
+)
+
+//The searchLocales map contains all country codes supported by Yelp, each
+//mapping to the languages that are supported for that country. The first
+//language of each country is the language Yelp uses by default.
+var searchLocales = map[string][]string{
+	"AR": {"es"},
+	"AT": {"de", "en"},
+	"AU": {"en"},
+	"BE": {"nl", "fr", "en"},
+	"BR": {"pt"},
+	"CA": {"en", "fr"},
+	"CH": {"de", "fr", "it", "en"},
+	"CL": {"es"},
+	"CZ": {"cs", "en"},
+	"DE": {"de", "en"},
+	"DK": {"da", "en"},
+	"ES": {"es", "en"},
+	"FI": {"fi", "sv", "en"},
+	"FR": {"fr", "en"},
+	"GB": {"en"},
+	"HK": {"zh", "en"},
+	"IE": {"en"},
+	"IT": {"it", "en"},
+	"JP": {"ja", "en"},
+	"MX": {"es"},
+	"MY": {"en"},
+	"NL": {"nl", "en"},
+	"NO": {"nb", "en"},
+	"NZ": {"en"},
+	"PH": {"en"},
+	"PL": {"pl", "en"},
+	"PT": {"pt", "en"},
+	"SE": {"sv", "en"},
+	"SG": {"en"},
+	"TR": {"tr", "en"},
+	"TW": {"zh", "en"},
+	"US": {"en"},
+}
+
+//validLocale returns true when the provided language is supported by Yelp for
+//the provided country code
+func validLocale(countryCode, language string) bool {
+	for _, v := range searchLocales[countryCode] {
+		if v == language {
+			return true
+		}
+	}
+
+	return false
+}
+
+//validLanguage returns true when the provided language is supported by Yelp
+//for any of the supported countries
+func validLanguage(language string) bool {
+	for countryCode := range searchLocales {
+		if validLocale(countryCode, language) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//SearchCountryCode is a search option specifying the ISO 3166-1 alpha-2 code
+//of the country whose Yelp site should be used to parse the location and to
+//present the results (e.g. "NL" or "DE"). It can be used with all Yelp API
+//endpoints accepting a 'cc' parameter.
+type SearchCountryCode string
+
+func (scc SearchCountryCode) Query(sq *SearchQuery) error {
+	//make sure the country code has not been set already
+	if sq.mask&searchBitMaskCountryCode != 0 {
+		return Error{ErrorTypeInvalidArgumentRepetition, "SearchCountryCode", "Attempting to set the country code a second time"}
+	}
+
+	//make sure the country code is supported
+	if _, ok := searchLocales[string(scc)]; !ok {
+		return Error{ErrorTypeInvalidArgumentDefinition, "SearchCountryCode", fmt.Sprintf("Unsupported country code: %s", string(scc))}
+	}
+
+	//if a language is already specified it should be supported by the country
+	if language, ok := sq.Get(searchLanguageKey); ok && !validLocale(string(scc), language) {
+		return Error{ErrorTypeInvalidArgumentDefinition, "SearchCountryCode",
+			fmt.Sprintf("Language '%s' is not supported for country code '%s'", language, string(scc))}
+	}
+
+	//add query, update mask and return
+	sq.Append(searchCountryCodeKey, string(scc))
+
+	sq.mask |= searchBitMaskCountryCode
+	return nil
+}
+
+//SearchLanguage is a search option specifying the ISO 639 language code in
+//which the results should be presented (e.g. "nl" or "de"). When combined with
+//SearchCountryCode the language has to be supported for that country. It can
+//be used with all Yelp API endpoints accepting a 'lang' parameter.
+type SearchLanguage string
+
+func (sl SearchLanguage) Query(sq *SearchQuery) error {
+	//make sure the language has not been set already
+	if sq.mask&searchBitMaskLanguage != 0 {
+		return Error{ErrorTypeInvalidArgumentRepetition, "SearchLanguage", "Attempting to set the language a second time"}
+	}
+
+	//make sure the language is supported, either in combination with the
+	//country code or by any country
+	if countryCode, ok := sq.Get(searchCountryCodeKey); ok {
+		if !validLocale(countryCode, string(sl)) {
+			return Error{ErrorTypeInvalidArgumentDefinition, "SearchLanguage",
+				fmt.Sprintf("Language '%s' is not supported for country code '%s'", string(sl), countryCode)}
+		}
+	} else if !validLanguage(string(sl)) {
+		return Error{ErrorTypeInvalidArgumentDefinition, "SearchLanguage", fmt.Sprintf("Unsupported language: %s", string(sl))}
+	}
+
+	//add query, update mask and return
+	sq.Append(searchLanguageKey, string(sl))
+
+	sq.mask |= searchBitMaskLanguage
+	return nil
+}
+
+//SearchLanguageFilter is a boolean search option whether Yelp should only
+//return reviews written in the language specified by SearchLanguage.
+type SearchLanguageFilter bool
+
+func (slf SearchLanguageFilter) Query(sq *SearchQuery) error {
+	//make sure the language filter has not been set already
+	if sq.mask&searchBitMaskLanguageFilter != 0 {
+		return Error{ErrorTypeInvalidArgumentRepetition, "SearchLanguageFilter", "Attempting to set the language filter a second time"}
+	}
+
+	//add query, update mask and return
+	sq.Append(searchLanguageFilterKey, strconv.FormatBool(bool(slf)))
+
+	sq.mask |= searchBitMaskLanguageFilter
+	return nil
+}
+
+//SearchActionLinks is a boolean search option whether Yelp should include
+//action links (e.g. links to reserve a table or order food) in the results.
+type SearchActionLinks bool
+
+func (sal SearchActionLinks) Query(sq *SearchQuery) error {
+	//make sure the action links option has not been set already
+	if sq.mask&searchBitMaskActionLinks != 0 {
+		return Error{ErrorTypeInvalidArgumentRepetition, "SearchActionLinks", "Attempting to set the action links option a second time"}
+	}
+
+	//add query, update mask and return
+	sq.Append(searchActionLinksKey, strconv.FormatBool(bool(sal)))
+
+	sq.mask |= searchBitMaskActionLinks
+	return nil
+}
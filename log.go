@@ -0,0 +1,65 @@
+package yelp
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+//redactedParameters matches the values of the OAuth parameters that should
+//never be logged, both as plain query elements and percent encoded (possibly
+//twice) within a signature base string, and quoted (possibly with an escaped
+//quote) within an Authorization header. A value ends at the next '&', its
+//percent encoded equivalent or a quote
+var redactedParameters = regexp.MustCompile(`(oauth_signature|oauth_token|oauth_consumer_key)(=|%3D|%253D)((?:\\?")?)(?:[^&\s"\\%]|%(?:[013-9A-Fa-f][0-9A-Fa-f]|2[0-57-9A-Fa-f]))*`)
+
+//redactedBearer matches bearer tokens as used in Authorization headers
+var redactedBearer = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+
+//redact replaces all secrets in the provided text (e.g. a URL or an error
+//message) by the text "REDACTED"
+func redact(text string) string {
+	text = redactedParameters.ReplaceAllString(text, "${1}${2}${3}REDACTED")
+	return redactedBearer.ReplaceAllString(text, "${1}REDACTED")
+}
+
+//SetLogger enables logging of every request performed by the client to the
+//provided logger, or disables it when the logger is nil. Successful requests
+//are logged at the info level, failed requests at the error level. The OAuth
+//signature, token and consumer key, as well as bearer tokens, are always
+//redacted from the logged messages.
+func (c *Client) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+//logMiddleware returns the handler logging every request passed to the
+//provided handler
+func (c Client) logMiddleware(next Handler) Handler {
+	logger := c.logger
+
+	return func(r *Request) (*Response, error) {
+		start := time.Now()
+		response, err := next(r)
+
+		attributes := []slog.Attr{slog.String("query", redact(r.Query.String())),
+			slog.Duration("latency", time.Since(start))}
+
+		if r.HTTP != nil {
+			attributes = append(attributes, slog.String("method", r.HTTP.Method), slog.String("url", redact(r.HTTP.URL.String())))
+		}
+
+		if response != nil {
+			attributes = append(attributes, slog.Int("status", response.StatusCode), slog.Bool("cache_hit", response.CacheHit))
+		}
+
+		if err != nil {
+			attributes = append(attributes, slog.String("error", redact(err.Error())))
+			logger.LogAttrs(context.Background(), slog.LevelError, "Yelp request failed", attributes...)
+		} else {
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "Yelp request", attributes...)
+		}
+
+		return response, err
+	}
+}
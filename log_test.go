@@ -0,0 +1,55 @@
+package yelp
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	toAttempt := []string{"http://api.yelp.com/v2/search?location=Delft&oauth_consumer_key=abc&oauth_signature=a%2Bb%3D&oauth_token=def",
+		"GET&http%3A%2F%2Fapi.yelp.com&location%3DDelft%26oauth_consumer_key%3Dabc%26oauth_token%3Ddef",
+		"Authorization: Bearer abc.def-ghi=",
+		`Authorization: OAuth oauth_consumer_key="abc", oauth_nonce="xyz", oauth_signature="r6%2FTJ%3D", oauth_token="def"`,
+		`msg="header" value="OAuth oauth_consumer_key=\"abc\", oauth_token=\"def\""`}
+	expected := []string{"http://api.yelp.com/v2/search?location=Delft&oauth_consumer_key=REDACTED&oauth_signature=REDACTED&oauth_token=REDACTED",
+		"GET&http%3A%2F%2Fapi.yelp.com&location%3DDelft%26oauth_consumer_key%3DREDACTED%26oauth_token%3DREDACTED",
+		"Authorization: Bearer REDACTED",
+		`Authorization: OAuth oauth_consumer_key="REDACTED", oauth_nonce="xyz", oauth_signature="REDACTED", oauth_token="REDACTED"`,
+		`msg="header" value="OAuth oauth_consumer_key=\"REDACTED\", oauth_token=\"REDACTED\""`}
+
+	for i := range toAttempt {
+		if result := redact(toAttempt[i]); result != expected[i] {
+			t.Errorf("redact('%s') was '%s', expected '%s'", toAttempt[i], result, expected[i])
+		}
+	}
+
+	//the header produced by the signer is redacted entirely
+	header, _ := Signer{ConsumerKey: "consumerkey", ConsumerSecret: "secret", Token: "usertoken", TokenSecret: "tokensecret"}.
+		AuthorizationHeader("GET", "http://api.yelp.com/v2/search?location=Delft", nil)
+	redacted := redact(header)
+	if strings.Contains(redacted, "consumerkey") || strings.Contains(redacted, "usertoken") ||
+		!strings.Contains(redacted, `oauth_signature="REDACTED"`) {
+		t.Errorf("Expected the Authorization header to be redacted, got '%s'", redacted)
+	}
+}
+
+func TestLoggerRedaction(t *testing.T) {
+	var output bytes.Buffer
+	c := stubClient(t, func(r *Request) (*Response, error) {
+		return nil, Error{ErrorTypeHTTPFailure, "Test", "Failed to get " + r.HTTP.URL.String()}
+	}).withCredentials(Credentials{"consumerkey", "secret", "usertoken", "tokensecret", ""})
+	c.SetLogger(slog.New(slog.NewTextHandler(&output, nil)))
+
+	_, err := c.SearchOptions(SearchLocation("Delft"))
+	if err == nil {
+		t.Fatalf("Expected the search to fail")
+	}
+
+	for _, v := range []string{output.String(), err.Error()} {
+		if strings.Contains(v, "consumerkey") || strings.Contains(v, "usertoken") || !strings.Contains(v, "oauth_signature=REDACTED") {
+			t.Errorf("Expected all secrets to be redacted from '%s'", v)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package yelp
+
+import (
+	"errors"
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//metricsLatencyBounds are the upper bounds of the buckets of the latency
+//histogram. Latencies above the last bound are counted in an additional bucket
+var metricsLatencyBounds = [...]time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+//metricsQuotaHeaders are the response headers from which the remaining request
+//quota is read, in order of preference
+var metricsQuotaHeaders = [...]string{"RateLimit-Remaining", "X-RateLimit-Remaining"}
+
+//metricsPublishLock serializes publishing metrics, as the expvar package panics
+//when a name is published twice
+var metricsPublishLock sync.Mutex
+
+//The LatencyBucket structure is a single bucket of the latency histogram in
+//Stats. It counts all requests that took at most UpperBound, but longer than
+//the UpperBound of the previous bucket. The last bucket has no upper bound, and
+//has an UpperBound of zero.
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      int64
+}
+
+//The Stats structure is a snapshot of the metrics of a Client, as returned by
+//Client.Stats(). Errors counts the failed requests by the string value of
+//their ErrorType. RemainingQuota is the number of requests Yelp reported to be
+//remaining, or -1 when Yelp has not reported it.
+type Stats struct {
+	Requests       int64
+	Errors         map[string]int64
+	Retries        int64
+	CacheHits      int64
+	RemainingQuota int64
+	Latency        []LatencyBucket
+}
+
+//The metrics structure collects the metrics of a single client. It is shared
+//by all copies of the client
+type metrics struct {
+	lock    sync.Mutex
+	stats   Stats
+	buckets [len(metricsLatencyBounds) + 1]int64
+}
+
+//newMetrics creates a new and empty metrics collection
+func newMetrics() *metrics {
+	return &metrics{stats: Stats{Errors: make(map[string]int64), RemainingQuota: -1}}
+}
+
+//middleware returns the handler recording the metrics of every request passed
+//to the provided handler
+func (m *metrics) middleware(next Handler) Handler {
+	return func(r *Request) (*Response, error) {
+		start := time.Now()
+		response, err := next(r)
+		m.record(time.Since(start), response, err)
+		return response, err
+	}
+}
+
+//record adds the result of a single request to the metrics
+func (m *metrics) record(latency time.Duration, response *Response, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.stats.Requests++
+
+	bucket := 0
+	for bucket < len(metricsLatencyBounds) && latency > metricsLatencyBounds[bucket] {
+		bucket++
+	}
+
+	m.buckets[bucket]++
+
+	if err != nil {
+		var e Error
+
+		if errors.As(err, &e) {
+			m.stats.Errors[e.EType.String()]++
+		} else {
+			m.stats.Errors["Unknown"]++
+		}
+	}
+
+	if response == nil {
+		return
+	}
+
+	if response.CacheHit {
+		m.stats.CacheHits++
+	}
+
+	m.stats.Retries += int64(response.Retries)
+
+	for _, v := range metricsQuotaHeaders {
+		if remaining, err := strconv.ParseInt(response.Header.Get(v), 10, 64); err == nil {
+			m.stats.RemainingQuota = remaining
+			break
+		}
+	}
+}
+
+//snapshot returns a copy of the current metrics
+func (m *metrics) snapshot() Stats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	stats := m.stats
+	stats.Errors = make(map[string]int64, len(m.stats.Errors))
+
+	for k, v := range m.stats.Errors {
+		stats.Errors[k] = v
+	}
+
+	stats.Latency = make([]LatencyBucket, len(m.buckets))
+
+	for i, v := range m.buckets {
+		if i < len(metricsLatencyBounds) {
+			stats.Latency[i].UpperBound = metricsLatencyBounds[i]
+		}
+
+		stats.Latency[i].Count = v
+	}
+
+	return stats
+}
+
+//Stats returns a snapshot of the metrics collected for all requests performed
+//by the client.
+func (c Client) Stats() Stats {
+	if c.metrics == nil {
+		return newMetrics().snapshot()
+	}
+
+	return c.metrics.snapshot()
+}
+
+//Publish publishes the metrics of the client through the expvar package under
+//the name "yelp.<name>", such that they are served by the /debug/vars handler.
+//An error is returned when the name is already in use.
+func (c *Client) Publish(name string) error {
+	name = "yelp." + name
+
+	metricsPublishLock.Lock()
+	defer metricsPublishLock.Unlock()
+
+	if expvar.Get(name) != nil {
+		return Error{ErrorTypeInvalidArgumentRepetition, "Client", "Metrics are already published as " + name}
+	}
+
+	if c.metrics == nil {
+		c.metrics = newMetrics()
+	}
+
+	m := c.metrics
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return m.snapshot()
+	}))
+
+	return nil
+}
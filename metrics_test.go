@@ -0,0 +1,76 @@
+package yelp
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+//testPublished counts the metrics published by the tests
+var testPublished int
+
+func TestMetrics(t *testing.T) {
+	c := stubClient(t, func(r *Request) (*Response, error) {
+		if r.Query.queries[0].Value == "fail" {
+			return nil, Error{ErrorTypeHTTPFailure, "Test", "Failed"}
+		}
+
+		header := make(http.Header)
+		header.Set("RateLimit-Remaining", "42")
+		return &Response{Businesses: &Businesses{}, Header: header, CacheHit: true, Retries: 2}, nil
+	})
+
+	c.SearchOptions(SearchLocation("Delft"))
+	c.SearchOptions(SearchLocation("fail"))
+
+	stats := c.Stats()
+	if stats.Requests != 2 || stats.CacheHits != 1 || stats.Retries != 2 || stats.RemainingQuota != 42 {
+		t.Errorf("Expected 2 requests, 1 cache hit, 2 retries and 42 remaining, got '%+v'", stats)
+	}
+
+	if stats.Errors[ErrorTypeHTTPFailure.String()] != 1 {
+		t.Errorf("Expected a single HTTP failure, got '%v'", stats.Errors)
+	}
+
+	//expvar names are global, so every run of the test uses a new name
+	testPublished++
+	name := fmt.Sprintf("metrics-test-%d", testPublished)
+
+	if err := c.Publish(name); err != nil {
+		t.Errorf("Expected publishing metrics to succeed: %v", err)
+	}
+
+	if err := c.Publish(name); err == nil {
+		t.Errorf("Expected publishing metrics under the same name to fail")
+	}
+}
+
+func TestMetricsConcurrentPublish(t *testing.T) {
+	c := New("http://api.yelp.com/v2/search", "key", "secret", "token", "tokensecret")
+	testPublished++
+	name := fmt.Sprintf("metrics-concurrent-%d", testPublished)
+
+	var wait sync.WaitGroup
+	var lock sync.Mutex
+	published := 0
+
+	for i := 0; i < 8; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+
+			if c.Publish(name) == nil {
+				lock.Lock()
+				published++
+				lock.Unlock()
+			}
+		}()
+	}
+
+	wait.Wait()
+
+	if published != 1 {
+		t.Errorf("Expected the metrics to be published exactly once, got %d", published)
+	}
+}
@@ -0,0 +1,81 @@
+package yelp
+
+import (
+	"net/http"
+	"time"
+)
+
+//The Request structure describes a single request to the Yelp API as it passes
+//through the middleware chain. Query contains the query elements before the
+//OAuth elements were added, HTTP is the signed request that will be sent to
+//Yelp. Middleware may modify HTTP, for example to add headers. Signature
+//describes how the request was signed, which can be used to diagnose signature
+//errors reported by Yelp.
+type Request struct {
+	Query     SearchQuery
+	HTTP      *http.Request
+	Signature SignatureDebug
+	key       *poolKey
+}
+
+//The Response structure describes the response of Yelp to a Request. Latency
+//is the time between sending the request and reading the entire response.
+//Middleware short-circuiting a request only has to provide the Businesses, and
+//should set CacheHit when they were served from a cache. Middleware that sends
+//a request more than once should report the additional attempts in Retries.
+//ErrorID is the identifier of the error Yelp responded with (e.g.
+//EXCEEDED_REQS), if any.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Latency    time.Duration
+	Businesses *Businesses
+	CacheHit   bool
+	Retries    int
+	ErrorID    string
+}
+
+//A Handler performs a Request. The returned Response may be non-nil even when
+//an error is returned, for example when Yelp responded with an error message.
+type Handler func(*Request) (*Response, error)
+
+//A Middleware wraps the next Handler in the chain. It can observe or modify the
+//request before passing it to the next handler, observe the response and the
+//final error afterwards, or short-circuit the request by not calling the next
+//handler at all.
+type Middleware func(next Handler) Handler
+
+//Use appends middleware to the chain through which every request of the client
+//is passed. The first middleware added is the first to receive a request.
+func (c *Client) Use(middleware ...Middleware) {
+	//copy the chain, such that copies of the client do not share it
+	c.middleware = append(append([]Middleware(nil), c.middleware...), middleware...)
+}
+
+//handler returns the complete middleware chain, ending in the handler that
+//actually sends the request to Yelp
+func (c Client) handler() Handler {
+	h := Handler(c.send)
+
+	//failed keys of the credential pool are replaced right before sending, such
+	//that every middleware observes a single request
+	if c.pool != nil {
+		h = c.pool.middleware(c, h)
+	}
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+
+	if c.logger != nil {
+		h = c.logMiddleware(h)
+	}
+
+	//the metrics observe the entire chain, including cache hits and retries
+	//reported by the middleware
+	if c.metrics != nil {
+		h = c.metrics.middleware(h)
+	}
+
+	return h
+}
@@ -0,0 +1,66 @@
+package yelp
+
+import (
+	"strings"
+	"testing"
+)
+
+//stubClient creates a client whose requests are answered by the provided
+//handler instead of Yelp. The requests first pass through the provided
+//middleware
+func stubClient(t *testing.T, respond Handler, middleware ...Middleware) *Client {
+	t.Helper()
+
+	c := New("http://api.yelp.com/v2/search", "key", "secret", "token", "tokensecret")
+	c.Use(middleware...)
+	c.Use(func(Handler) Handler {
+		return respond
+	})
+
+	return c
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	var order []string
+	var observed error
+
+	//the outer middleware observes, the stub short-circuits
+	outer := func(next Handler) Handler {
+		return func(r *Request) (*Response, error) {
+			order = append(order, "outer")
+			r.HTTP.Header.Set("X-Test", "injected")
+			response, err := next(r)
+			observed = err
+			return response, err
+		}
+	}
+
+	c := stubClient(t, func(r *Request) (*Response, error) {
+		order = append(order, "inner")
+
+		for _, v := range r.Query.queries {
+			if strings.HasPrefix(v.Name, "oauth_") {
+				t.Errorf("Expected the query passed to middleware to be unsigned, found '%s'", v.Name)
+			}
+		}
+
+		if r.HTTP.URL.Query().Get("oauth_signature") == "" {
+			t.Errorf("Expected the HTTP request passed to middleware to be signed")
+		}
+
+		if r.HTTP.Header.Get("X-Test") != "injected" {
+			t.Errorf("Expected the header injected by the outer middleware to be present")
+		}
+
+		return &Response{Businesses: &Businesses{Total: 1}}, nil
+	}, outer)
+
+	businesses, err := c.SearchOptions(SearchLocation("Delft"))
+	if err != nil || businesses.Total != 1 {
+		t.Fatalf("Expected the short-circuited search to succeed, got '%v', '%v'", businesses, err)
+	}
+
+	if strings.Join(order, ",") != "outer,inner" || observed != nil {
+		t.Errorf("Expected middleware to be invoked outer to inner without error, got '%v', '%v'", order, observed)
+	}
+}
@@ -4,66 +4,315 @@ import (
 	"bytes"
 	"crypto/hmac"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
-//The oauth structure represents all data and provides all required method tha
-//are necessary to sign a yelp api query.
-type oauth struct {
-	ConsumerKey string
-	Token       string
-	hashKey     []byte
+//The Signer structure represents all data and provides all required methods
+//that are necessary to sign a request following OAuth 1.0a (RFC 5849) using
+//HMAC-SHA1. It is used by the Client to sign Yelp API queries, but can sign
+//requests for any other OAuth 1.0a API as well.
+//
+//The Token and TokenSecret may be empty for requests that are only signed by
+//the consumer. When IncludeVersion is true the optional oauth_version parameter
+//is added. The Clock and Nonce default to the actual time and a secure random
+//nonce when nil. Encoding selects how the parameters are encoded, where
+//EncodingYelpLegacy is only meant for the Yelp API.
+type Signer struct {
+	ConsumerKey    string
+	ConsumerSecret string
+	Token          string
+	TokenSecret    string
+	IncludeVersion bool
+	Encoding       Encoding
+	Clock          func() time.Time
+	Nonce          NonceSource
 }
 
-//SetHashKey will create the hash key string following oauth 1.0 guidelines
-func (yoa *oauth) SetHashKey(consumerSecret string, tokenSecret string) {
-	//create hashing key
+//The SignatureDebug structure contains every intermediate result of signing a
+//request, such that signature mismatches reported by Yelp can be diagnosed.
+//Parameters are the sorted query elements as they were signed, and the
+//KeyFingerprint identifies the signing key without revealing it: it is the
+//first 8 bytes of the SHA-256 hash of the key, hexadecimally encoded.
+type SignatureDebug struct {
+	Method         string
+	URL            string
+	Parameters     []string
+	BaseString     string
+	KeyFingerprint string
+	Signature      string
+}
+
+//hashKey will create the hash key string following oauth 1.0 guidelines
+func (s Signer) hashKey() []byte {
 	var hashKey bytes.Buffer
-	hashKey.WriteString(percentEncode(consumerSecret))
+	hashKey.WriteString(percentEncode(s.ConsumerSecret))
 	hashKey.WriteString("&")
-	hashKey.WriteString(percentEncode(tokenSecret))
+	hashKey.WriteString(percentEncode(s.TokenSecret))
+
+	return hashKey.Bytes()
+}
+
+//Sign computes the OAuth parameters, including the signature, for a request
+//with the provided method and URL. The parameters in the query string of the
+//URL and the provided form-encoded body parameters (which may be nil) are part
+//of the signature. The returned parameters can be added to the query string,
+//the body or, through AuthorizationHeader(...), the headers of the request.
+func (s Signer) Sign(method, rawURL string, form url.Values) (url.Values, SignatureDebug, error) {
+	baseURL, query, err := normalizeURL(rawURL)
+
+	if err != nil {
+		return nil, SignatureDebug{}, err
+	}
+
+	//collect all parameters of the request
+	var elements SearchQuery
+	appendValues(&elements, query)
+	appendValues(&elements, form)
+	count := len(elements.queries)
+
+	debug, err := s.sign(strings.ToUpper(method), baseURL, &elements)
+
+	if err != nil {
+		return nil, debug, err
+	}
+
+	//the elements added by signing are the OAuth parameters
+	parameters := make(url.Values)
+
+	for _, v := range elements.queries[count:] {
+		parameters.Add(v.Name, v.Value)
+	}
+
+	return parameters, debug, nil
+}
+
+//SignURL signs a request with the provided method and URL and returns the URL
+//with the OAuth parameters added to its query string. The provided form-encoded
+//body parameters, which may be nil, are part of the signature.
+func (s Signer) SignURL(method, rawURL string, form url.Values) (string, error) {
+	parameters, _, err := s.Sign(method, rawURL, form)
+
+	if err != nil {
+		return "", err
+	}
+
+	u, _ := url.Parse(rawURL)
+	var elements SearchQuery
+	appendValues(&elements, u.Query())
+	appendValues(&elements, parameters)
+	u.RawQuery = elements.encode(EncodingRFC3986)
+
+	return u.String(), nil
+}
+
+//AuthorizationHeader signs a request with the provided method and URL and
+//returns the value of the Authorization header carrying the OAuth parameters.
+//The provided form-encoded body parameters, which may be nil, are part of the
+//signature.
+func (s Signer) AuthorizationHeader(method, rawURL string, form url.Values) (string, error) {
+	parameters, _, err := s.Sign(method, rawURL, form)
+
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(parameters))
+
+	for k := range parameters {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+
+	var header bytes.Buffer
+	header.WriteString("OAuth ")
+
+	for i, v := range names {
+		if i != 0 {
+			header.WriteString(", ")
+		}
+
+		header.WriteString(percentEncode(v))
+		header.WriteString("=\"")
+		header.WriteString(percentEncode(parameters.Get(v)))
+		header.WriteString("\"")
+	}
+
+	return header.String(), nil
+}
+
+//SignRequest signs the provided request and sets its Authorization header. The
+//parameters in the query string of the URL are part of the signature, as are
+//the parameters of the body when it is form-encoded. The body is restored after
+//reading it.
+func (s Signer) SignRequest(r *http.Request) error {
+	var form url.Values
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if r.Body != nil && contentType == "application/x-www-form-urlencoded" {
+		body, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+
+		if err != nil {
+			return Error{ErrorTypeReadFailure, "Signer", "Failed to read request body"}
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		form, err = url.ParseQuery(string(body))
+
+		if err != nil {
+			return Error{ErrorTypeOAuthFailure, "Signer", "Failed to parse form-encoded request body"}
+		}
+	}
+
+	header, err := s.AuthorizationHeader(r.Method, r.URL.String(), form)
+
+	if err != nil {
+		return err
+	}
 
-	//set hash key
-	yoa.hashKey = hashKey.Bytes()
+	r.Header.Set("Authorization", header)
+	return nil
 }
 
-//Sign will use the hash key and a HMAC-SHA1 algorithm to generate and sign a
-//signature. This signature, together with all other important oauth search query
-//elements, will be added to the SearchQuery.
-func (yoa *oauth) Sign(method string, url string, elements *SearchQuery) error {
-	//add the OAuth elements to the Yelp query
-	elements.Append("oauth_consumer_key", yoa.ConsumerKey)
-	elements.Append("oauth_nonce", nonce(30))
+//sign will use the hash key and a HMAC-SHA1 algorithm to generate and sign a
+//signature. This signature, together with all other important oauth search
+//query elements, will be added to the SearchQuery. The base URL must already be
+//normalized. All intermediate results are returned.
+func (s Signer) sign(method string, baseURL string, elements *SearchQuery) (SignatureDebug, error) {
+	//add the OAuth elements to the query
+	elements.Append("oauth_consumer_key", s.ConsumerKey)
+	elements.Append("oauth_nonce", s.nonce())
 	elements.Append("oauth_signature_method", "HMAC-SHA1")
-	elements.Append("oauth_timestamp", strconv.FormatInt(time.Now().Unix(), 10))
-	elements.Append("oauth_token", yoa.Token)
+	elements.Append("oauth_timestamp", strconv.FormatInt(s.now().Unix(), 10))
 
-	//sort all query elements
-	elements.Sort()
+	if s.Token != "" {
+		elements.Append("oauth_token", s.Token)
+	}
+
+	if s.IncludeVersion {
+		elements.Append("oauth_version", "1.0")
+	}
 
-	//create the signature
-	signature := strings.Join([]string{method, percentEncode(url), percentEncode(elements.String())}, "&")
+	//create the signature base string from the sorted and encoded elements
+	hashKey := s.hashKey()
+	debug := SignatureDebug{Method: method, URL: baseURL, KeyFingerprint: fingerprint(hashKey)}
+	debug.Parameters = elements.pairs(s.Encoding)
+	debug.BaseString = baseString(method, baseURL, strings.Join(debug.Parameters, "&"), s.Encoding)
 
-	//reset the hasher (could have been used before), then sign the signature
-	//yoa.Hasher.Reset()
-	hasher := hmac.New(sha1.New, yoa.hashKey)
+	//sign the signature base string
+	hasher := hmac.New(sha1.New, hashKey)
 	totalWritten := 0
 	curWritten := 0
 	var err error = nil
 
-	for totalWritten < len(signature) && err == nil {
-		curWritten, err = hasher.Write([]byte(signature[totalWritten:]))
+	for totalWritten < len(debug.BaseString) && err == nil {
+		curWritten, err = hasher.Write([]byte(debug.BaseString[totalWritten:]))
 		totalWritten += curWritten
 	}
 
 	if err != nil {
-		return Error{ErrorTypeWriteFailure, "oauth", "Hashing signature failed"}
+		return debug, Error{ErrorTypeWriteFailure, "oauth", "Hashing signature failed"}
 	}
 
-	//add the signature to the query and percent encode it
-	elements.Append("oauth_signature", percentEncode(base64.StdEncoding.EncodeToString(hasher.Sum(nil))))
-	return nil
+	//add the signature to the query. The legacy encoding does not encode the
+	//values in the query string, so the signature is added encoded
+	debug.Signature = base64.StdEncoding.EncodeToString(hasher.Sum(nil))
+
+	if s.Encoding == EncodingYelpLegacy {
+		elements.Append("oauth_signature", percentEncode(debug.Signature))
+	} else {
+		elements.Append("oauth_signature", debug.Signature)
+	}
+
+	return debug, nil
+}
+
+//baseString creates the signature base string from the request method, the URL
+//and the sorted and encoded parameters, as defined by RFC 5849 section 3.4.1
+func baseString(method, url, parameters string, e Encoding) string {
+	if e == EncodingYelpLegacy {
+		return strings.Join([]string{method, percentEncode(url), legacyPercentEncode(parameters)}, "&")
+	}
+
+	return strings.Join([]string{method, percentEncode(url), percentEncode(parameters)}, "&")
+}
+
+//normalizeURL splits an URL into the base string URI defined by RFC 5849
+//section 3.4.1.2 and the parameters of its query string
+func normalizeURL(rawURL string) (string, url.Values, error) {
+	u, err := url.Parse(rawURL)
+
+	if err != nil || u.Host == "" {
+		return "", nil, Error{ErrorTypeInvalidArgumentDefinition, "Signer", "Invalid URL: " + rawURL}
+	}
+
+	query, err := url.ParseQuery(u.RawQuery)
+
+	if err != nil {
+		return "", nil, Error{ErrorTypeInvalidArgumentDefinition, "Signer", "Invalid query string: " + rawURL}
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+
+	//only include the port when it is not the default port of the scheme
+	if port := u.Port(); port != "" && !(scheme == "http" && port == "80") && !(scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+
+	path := u.EscapedPath()
+
+	if path == "" {
+		path = "/"
+	}
+
+	return scheme + "://" + host + path, query, nil
+}
+
+//appendValues appends all provided values to the query
+func appendValues(q *SearchQuery, values url.Values) {
+	for k, v := range values {
+		for _, w := range v {
+			q.Append(k, w)
+		}
+	}
+}
+
+//now returns the current time according to the clock of the signer, or the
+//actual time when no clock is set
+func (s Signer) now() time.Time {
+	if s.Clock == nil {
+		return time.Now()
+	}
+
+	return s.Clock()
+}
+
+//nonce returns a new nonce from the nonce source of the signer, or from the
+//default secure nonce source when no source is set
+func (s Signer) nonce() string {
+	if s.Nonce == nil {
+		return nonce(30)
+	}
+
+	return s.Nonce(30)
+}
+
+//fingerprint returns a short identification of a hash key that cannot be used
+//to recover the key itself
+func fingerprint(hashKey []byte) string {
+	sum := sha256.Sum256(hashKey)
+	return hex.EncodeToString(sum[:8])
 }
@@ -0,0 +1,212 @@
+package yelp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugSignature(t *testing.T) {
+	c := New("http://api.yelp.com/v2/search", "key", "secret", "token", "tokensecret")
+
+	var q SearchQuery
+	q.Append("term", "food")
+	q.Append("location", "Delft")
+
+	debug, err := c.DebugSignature(q)
+	if err != nil {
+		t.Fatalf("Expected signing to succeed: %v", err)
+	}
+
+	//the signature should be the HMAC-SHA1 of the base string
+	hasher := hmac.New(sha1.New, []byte("secret&tokensecret"))
+	hasher.Write([]byte(debug.BaseString))
+
+	if signature := base64.StdEncoding.EncodeToString(hasher.Sum(nil)); signature != debug.Signature {
+		t.Errorf("Expected signature '%s', got '%s'", signature, debug.Signature)
+	}
+
+	if !strings.HasPrefix(debug.BaseString, "GET&http%3A%2F%2Fapi.yelp.com%2Fv2%2Fsearch&") {
+		t.Errorf("Unexpected base string '%s'", debug.BaseString)
+	}
+
+	if len(debug.Parameters) != 7 || !sort.StringsAreSorted(debug.Parameters) {
+		t.Errorf("Expected 7 sorted parameters, got '%v'", debug.Parameters)
+	}
+
+	if len(debug.KeyFingerprint) != 16 || strings.Contains(debug.KeyFingerprint, "secret") {
+		t.Errorf("Unexpected key fingerprint '%s'", debug.KeyFingerprint)
+	}
+
+	if len(q.queries) != 2 || q.queries[0].Name != "term" {
+		t.Errorf("Expected the debugged query not to be modified, got '%s'", q.String())
+	}
+}
+
+func TestParameterNormalization(t *testing.T) {
+	//the example of RFC 5849, section 3.4.1.3, with all values decoded
+	var q SearchQuery
+	q.Append("b5", "=%3D")
+	q.Append("a3", "a")
+	q.Append("c@", "")
+	q.Append("a2", "r b")
+	q.Append("oauth_consumer_key", "9djdj82h48djs9d2")
+	q.Append("oauth_token", "kkk9d7dh3k39sjv7")
+	q.Append("oauth_signature_method", "HMAC-SHA1")
+	q.Append("oauth_timestamp", "137131201")
+	q.Append("oauth_nonce", "7d8f3e4a")
+	q.Append("c2", "")
+	q.Append("a3", "2 q")
+
+	normalized := "a2=r%20b&a3=2%20q&a3=a&b5=%3D%253D&c%40=&c2=&oauth_consumer_key=9djdj82h48djs9d2" +
+		"&oauth_nonce=7d8f3e4a&oauth_signature_method=HMAC-SHA1&oauth_timestamp=137131201&oauth_token=kkk9d7dh3k39sjv7"
+
+	if result := q.encode(EncodingRFC3986); result != normalized {
+		t.Errorf("Expected normalized parameters '%s', got '%s'", normalized, result)
+	}
+
+	base := "POST&http%3A%2F%2Fexample.com%2Frequest&a2%3Dr%2520b%26a3%3D2%2520q%26a3%3Da%26b5%3D%253D%25253D%26c%2540%3D" +
+		"%26c2%3D%26oauth_consumer_key%3D9djdj82h48djs9d2%26oauth_nonce%3D7d8f3e4a%26oauth_signature_method%3DHMAC-SHA1" +
+		"%26oauth_timestamp%3D137131201%26oauth_token%3Dkkk9d7dh3k39sjv7"
+
+	if result := baseString("POST", "http://example.com/request", normalized, EncodingRFC3986); result != base {
+		t.Errorf("Expected base string '%s', got '%s'", base, result)
+	}
+}
+
+func TestLegacyEncoding(t *testing.T) {
+	var q SearchQuery
+	SearchLocation("Den Haag").Query(&q)
+	SearchTerms{"bar", "cafe"}.Query(&q)
+
+	if result := q.encode(EncodingYelpLegacy); result != "location=Den+Haag&term=bar,cafe" {
+		t.Errorf("Unexpected legacy query string '%s'", result)
+	}
+
+	if result := q.encode(EncodingRFC3986); result != "location=Den%20Haag&term=bar%2Ccafe" {
+		t.Errorf("Unexpected query string '%s'", result)
+	}
+
+	if result := baseString("GET", "http://api.yelp.com/v2/search", q.encode(EncodingYelpLegacy), EncodingYelpLegacy); result !=
+		"GET&http%3A%2F%2Fapi.yelp.com%2Fv2%2Fsearch&location%3DDen%2BHaag%26term%3Dbar%252Ccafe" {
+		t.Errorf("Unexpected legacy base string '%s'", result)
+	}
+}
+
+func TestSignedURLGolden(t *testing.T) {
+	var signed string
+	c := stubClient(t, func(r *Request) (*Response, error) {
+		signed = r.HTTP.URL.String()
+		return &Response{Businesses: &Businesses{}}, nil
+	})
+
+	c.SetClock(func() time.Time { return time.Unix(1400000000, 0) })
+	c.SetNonceSource(func(int) string { return "fixednonce" })
+
+	_, err := c.SearchOptions(SearchLocation("München"), SearchTerms{"bar", "cafe"})
+	if err != nil {
+		t.Fatalf("Expected the search to succeed: %v", err)
+	}
+
+	golden := "http://api.yelp.com/v2/search?location=M%C3%BCnchen&oauth_consumer_key=key&oauth_nonce=fixednonce" +
+		"&oauth_signature=aMeJZF4tjqiHXSOx03h%2FIkPIMKM%3D&oauth_signature_method=HMAC-SHA1" +
+		"&oauth_timestamp=1400000000&oauth_token=token&term=bar%2Ccafe"
+
+	if signed != golden {
+		t.Errorf("Expected signed URL '%s', got '%s'", golden, signed)
+	}
+}
+
+func TestNonce(t *testing.T) {
+	seen := make(map[string]bool)
+
+	for i := 0; i < 100; i++ {
+		n := nonce(30)
+
+		if len(n) != 30 || strings.Trim(n, nonceCharacters) != "" || seen[n] {
+			t.Fatalf("Expected a unique nonce of 30 valid characters, got '%s'", n)
+		}
+
+		seen[n] = true
+	}
+}
+
+func TestSignerCoreVector(t *testing.T) {
+	//the example of the OAuth Core 1.0 specification, appendix A.5
+	s := Signer{ConsumerKey: "dpf43f3p2l4k3l03", ConsumerSecret: "kd94hf93k423kf44",
+		Token: "nnch734d00sl2jdk", TokenSecret: "pfkkdhi9sl3r4s00", IncludeVersion: true,
+		Clock: func() time.Time { return time.Unix(1191242096, 0) },
+		Nonce: func(int) string { return "kllo9940pd9333jh" }}
+
+	parameters, debug, err := s.Sign("GET", "http://photos.example.net/photos?file=vacation.jpg&size=original", nil)
+	if err != nil {
+		t.Fatalf("Expected signing to succeed: %v", err)
+	}
+
+	base := "GET&http%3A%2F%2Fphotos.example.net%2Fphotos&file%3Dvacation.jpg%26oauth_consumer_key%3Ddpf43f3p2l4k3l03" +
+		"%26oauth_nonce%3Dkllo9940pd9333jh%26oauth_signature_method%3DHMAC-SHA1%26oauth_timestamp%3D1191242096" +
+		"%26oauth_token%3Dnnch734d00sl2jdk%26oauth_version%3D1.0%26size%3Doriginal"
+
+	if debug.BaseString != base {
+		t.Errorf("Expected base string '%s', got '%s'", base, debug.BaseString)
+	}
+
+	if signature := parameters.Get("oauth_signature"); signature != "tR3+Ty81lMeYAr/Fid0kMTYa/WM=" {
+		t.Errorf("Expected signature 'tR3+Ty81lMeYAr/Fid0kMTYa/WM=', got '%s'", signature)
+	}
+
+	signed, err := s.SignURL("GET", "http://photos.example.net/photos?file=vacation.jpg&size=original", nil)
+	if err != nil || !strings.Contains(signed, "&oauth_signature=tR3%2BTy81lMeYAr%2FFid0kMTYa%2FWM%3D&") {
+		t.Errorf("Expected the signature to be part of the signed URL, got '%s', '%v'", signed, err)
+	}
+}
+
+func TestSignerRequestVector(t *testing.T) {
+	//the example of RFC 5849, section 3.4.1.1, signed through the
+	//Authorization header of a request with a form-encoded body
+	s := Signer{ConsumerKey: "9djdj82h48djs9d2", ConsumerSecret: "j49sk3j29djd",
+		Token: "kkk9d7dh3k39sjv7", TokenSecret: "dh893hdasih9",
+		Clock: func() time.Time { return time.Unix(137131201, 0) },
+		Nonce: func(int) string { return "7d8f3e4a" }}
+
+	rawURL := "http://EXAMPLE.COM:80/request?b5=%3D%253D&a3=a&c%40=&a2=r%20b"
+	parameters, debug, err := s.Sign("POST", rawURL, url.Values{"c2": {""}, "a3": {"2 q"}})
+	if err != nil {
+		t.Fatalf("Expected signing to succeed: %v", err)
+	}
+
+	//the signature base string as printed in the RFC
+	base := "POST&http%3A%2F%2Fexample.com%2Frequest&a2%3Dr%2520b%26a3%3D2%2520q" +
+		"%26a3%3Da%26b5%3D%253D%25253D%26c%2540%3D%26c2%3D%26oauth_consumer_" +
+		"key%3D9djdj82h48djs9d2%26oauth_nonce%3D7d8f3e4a%26oauth_signature_m" +
+		"ethod%3DHMAC-SHA1%26oauth_timestamp%3D137131201%26oauth_token%3Dkkk" +
+		"9d7dh3k39sjv7"
+
+	if debug.BaseString != base {
+		t.Errorf("Expected base string '%s', got '%s'", base, debug.BaseString)
+	}
+
+	//signing the request itself signs the same base string
+	r, _ := http.NewRequest("POST", rawURL, strings.NewReader("c2&a3=2+q"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if err := s.SignRequest(r); err != nil {
+		t.Fatalf("Expected signing the request to succeed: %v", err)
+	}
+
+	signature := `oauth_signature="` + percentEncode(parameters.Get("oauth_signature")) + `"`
+	if header := r.Header.Get("Authorization"); !strings.Contains(header, signature) {
+		t.Errorf("Expected Authorization header '%s' to contain '%s'", header, signature)
+	}
+
+	if body, _ := ioutil.ReadAll(r.Body); string(body) != "c2&a3=2+q" {
+		t.Errorf("Expected the request body to be restored, got '%s'", body)
+	}
+}
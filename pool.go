@@ -0,0 +1,250 @@
+package yelp
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+//PoolStrategy determines which credentials of a CredentialPool are used for
+//the next request.
+type PoolStrategy byte
+
+const (
+	//PoolRoundRobin uses the available credentials in turn
+	PoolRoundRobin PoolStrategy = iota
+	//PoolLeastUsed uses the available credentials that performed the fewest
+	//requests
+	PoolLeastUsed
+)
+
+//poolRateLimitErrors are the IDs of the errors Yelp returns when the request
+//quota of a key is exhausted
+var poolRateLimitErrors = [...]string{"EXCEEDED_REQS", "ACCESS_LIMIT_REACHED", "TOO_MANY_REQUESTS_PER_SECOND"}
+
+//poolCredentialErrors are the IDs of the errors Yelp returns when a key is not
+//valid (anymore)
+var poolCredentialErrors = [...]string{"INVALID_OAUTH_CREDENTIALS", "INVALID_OAUTH_USER", "ACCOUNT_UNCONFIRMED",
+	"TOKEN_INVALID", "UNAUTHORIZED_ACCESS_TOKEN"}
+
+//The poolKey structure contains a single set of credentials of a pool together
+//with its usage
+type poolKey struct {
+	credentials Credentials
+	requests    int64
+	quarantine  time.Time
+}
+
+//The CredentialPool structure spreads the requests of a Client over multiple
+//sets of credentials. When Yelp reports that the request quota of a key is
+//exhausted, or that its credentials are invalid, the key is quarantined and the
+//request is signed again with the next available key. A key is available again
+//once its quarantine has passed. A CredentialPool is safe for concurrent use
+//and should be created through NewCredentialPool(...).
+type CredentialPool struct {
+	lock       sync.Mutex
+	strategy   PoolStrategy
+	quarantine time.Duration
+	keys       []*poolKey
+	next       int
+}
+
+//NewCredentialPool creates a pool from the provided credentials, which are
+//selected following the provided strategy. Keys that fail are quarantined for
+//the provided duration. An error is returned when no credentials are provided
+//or any of them is invalid.
+func NewCredentialPool(strategy PoolStrategy, quarantine time.Duration, credentials ...Credentials) (*CredentialPool, error) {
+	if len(credentials) == 0 {
+		return nil, Error{ErrorTypeCredentialFailure, "CredentialPool", "At least one set of credentials is required"}
+	}
+
+	if strategy != PoolRoundRobin && strategy != PoolLeastUsed {
+		return nil, Error{ErrorTypeInvalidArgumentDefinition, "CredentialPool", "Unknown pool strategy"}
+	}
+
+	p := &CredentialPool{strategy: strategy, quarantine: quarantine, keys: make([]*poolKey, len(credentials))}
+
+	for i, v := range credentials {
+		if err := v.Validate(); err != nil {
+			return nil, err
+		}
+
+		p.keys[i] = &poolKey{credentials: v}
+	}
+
+	return p, nil
+}
+
+//NewFromCredentialPool creates a new client from the provided URL that signs
+//every request with credentials from the provided pool. Multiple clients may
+//share the same pool.
+func NewFromCredentialPool(URL string, pool *CredentialPool) *Client {
+	c := New(URL, "", "", "", "")
+	c.pool = pool
+
+	return c
+}
+
+//Available returns the number of keys in the pool that are not quarantined.
+func (p *CredentialPool) Available() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	available := 0
+	now := time.Now()
+
+	for _, v := range p.keys {
+		if !now.Before(v.quarantine) {
+			available++
+		}
+	}
+
+	return available
+}
+
+//acquire selects the next available key that is not excluded and records its
+//use. An error is returned when no key is available
+func (p *CredentialPool) acquire(exclude map[*poolKey]bool) (*poolKey, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	selected, index, err := p.selectKey(exclude)
+
+	if err != nil {
+		return nil, err
+	}
+
+	selected.requests++
+	p.next = index + 1
+	return selected, nil
+}
+
+//peek returns the key the next request would use without recording its use,
+//such that the selection of later requests is not affected
+func (p *CredentialPool) peek() (*poolKey, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	selected, _, err := p.selectKey(nil)
+	return selected, err
+}
+
+//selectKey returns the next available key that is not excluded together with
+//its index. The lock of the pool has to be held
+func (p *CredentialPool) selectKey(exclude map[*poolKey]bool) (*poolKey, int, error) {
+	now := time.Now()
+	var selected *poolKey
+	index := 0
+
+	for i := range p.keys {
+		//round robin starts searching at the key after the last one used
+		j := i
+
+		if p.strategy == PoolRoundRobin {
+			j = (p.next + i) % len(p.keys)
+		}
+
+		v := p.keys[j]
+
+		if exclude[v] || now.Before(v.quarantine) {
+			continue
+		}
+
+		if selected == nil || (p.strategy == PoolLeastUsed && v.requests < selected.requests) {
+			selected = v
+			index = j
+		}
+
+		if p.strategy == PoolRoundRobin {
+			break
+		}
+	}
+
+	if selected == nil {
+		return nil, 0, Error{ErrorTypeCredentialFailure, "CredentialPool", "No credentials are available, all keys are quarantined"}
+	}
+
+	return selected, index, nil
+}
+
+//release quarantines the key when the response shows that its quota is
+//exhausted or its credentials are invalid. It returns true when the key was
+//quarantined
+func (p *CredentialPool) release(key *poolKey, response *Response) bool {
+	if response == nil || !poolKeyFailed(response) {
+		return false
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	key.quarantine = time.Now().Add(p.quarantine)
+	return true
+}
+
+//poolKeyFailed returns true when the response reports an error caused by the
+//key that signed the request rather than by the request itself
+func poolKeyFailed(response *Response) bool {
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+
+	for _, v := range poolRateLimitErrors {
+		if response.ErrorID == v {
+			return true
+		}
+	}
+
+	for _, v := range poolCredentialErrors {
+		if response.ErrorID == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+//middleware returns the handler that signs the request again with the next
+//available key whenever the key used for the request failed. The additional
+//attempts are reported in the Retries of the response
+func (p *CredentialPool) middleware(c Client, next Handler) Handler {
+	return func(r *Request) (*Response, error) {
+		response, err := next(r)
+		retries := 0
+		tried := make(map[*poolKey]bool)
+
+		for r.key != nil && p.release(r.key, response) {
+			tried[r.key] = true
+			key, e := p.acquire(tried)
+
+			if e != nil {
+				//no other key is available, report the failure of the last key
+				break
+			}
+
+			retry, e := c.withCredentials(key.credentials).authenticate(r.Query)
+
+			if e != nil {
+				return response, e
+			}
+
+			//keep the headers added by the middleware, except the credentials
+			for k, v := range r.HTTP.Header {
+				if k != "Authorization" {
+					retry.HTTP.Header[k] = v
+				}
+			}
+
+			retry.key = key
+			r = retry
+			response, err = next(r)
+			retries++
+		}
+
+		if response != nil {
+			response.Retries += retries
+		}
+
+		return response, err
+	}
+}
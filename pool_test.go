@@ -0,0 +1,137 @@
+package yelp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testPoolCredentials(keys ...string) []Credentials {
+	credentials := make([]Credentials, len(keys))
+
+	for i, v := range keys {
+		credentials[i] = Credentials{v, "secret", "token", "tokensecret", ""}
+	}
+
+	return credentials
+}
+
+func TestCredentialPoolStrategies(t *testing.T) {
+	p, err := NewCredentialPool(PoolRoundRobin, time.Minute, testPoolCredentials("a", "b", "c")...)
+	if err != nil {
+		t.Fatalf("Failed to create credential pool: %v", err)
+	}
+
+	var order []string
+	for i := 0; i < 4; i++ {
+		key, _ := p.acquire(nil)
+		order = append(order, key.credentials.ConsumerKey)
+	}
+
+	if order[0] != "a" || order[1] != "b" || order[2] != "c" || order[3] != "a" {
+		t.Errorf("Expected the keys to be used round robin, got '%v'", order)
+	}
+
+	//debugging a signature does not affect the selection
+	c := NewFromCredentialPool("http://api.yelp.com/v2/search", p)
+	debug, err := c.DebugSignature(SearchQuery{})
+	expected, _ := c.withCredentials(p.keys[1].credentials).DebugSignature(SearchQuery{})
+	if err != nil || debug.KeyFingerprint != expected.KeyFingerprint {
+		t.Errorf("Expected the next key to be used for debugging, got '%v'", err)
+	}
+
+	if key, _ := p.acquire(nil); key.credentials.ConsumerKey != "b" || key.requests != 2 {
+		t.Errorf("Expected debugging not to use a key, got '%s' with %d requests", key.credentials.ConsumerKey, key.requests)
+	}
+
+	p, _ = NewCredentialPool(PoolLeastUsed, time.Minute, testPoolCredentials("a", "b")...)
+	p.keys[0].requests = 5
+
+	for i := 0; i < 3; i++ {
+		if key, _ := p.acquire(nil); key.credentials.ConsumerKey != "b" {
+			t.Errorf("Expected the least used key to be selected, got '%s'", key.credentials.ConsumerKey)
+		}
+	}
+
+	p.release(p.keys[1], &Response{ErrorID: "EXCEEDED_REQS"})
+	if key, _ := p.acquire(nil); key.credentials.ConsumerKey != "a" || p.Available() != 1 {
+		t.Errorf("Expected the quarantined key to be skipped")
+	}
+
+	if _, err = NewCredentialPool(PoolRoundRobin, time.Minute); err == nil {
+		t.Errorf("Expected creating an empty credential pool to fail")
+	}
+
+	if _, err = NewCredentialPool(PoolRoundRobin, time.Minute, Credentials{ConsumerKey: "a"}); err == nil {
+		t.Errorf("Expected creating a credential pool with incomplete credentials to fail")
+	}
+}
+
+func TestCredentialPoolRetry(t *testing.T) {
+	var used []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("oauth_consumer_key")
+		used = append(used, key)
+
+		if r.Header.Get("X-Test") != "injected" {
+			t.Errorf("Expected the headers added by middleware to be kept for key '%s'", key)
+		}
+
+		switch key {
+		case "limited":
+			w.Write([]byte(`{"error": {"text": "Exceeded requests", "id": "EXCEEDED_REQS"}}`))
+		case "invalid":
+			w.Write([]byte(`{"error": {"text": "Invalid credentials", "id": "INVALID_OAUTH_CREDENTIALS"}}`))
+		default:
+			w.Write([]byte(`{"total": 1, "businesses": []}`))
+		}
+	}))
+	defer server.Close()
+
+	p, _ := NewCredentialPool(PoolRoundRobin, time.Hour, testPoolCredentials("limited", "invalid", "valid")...)
+	c := NewFromCredentialPool(server.URL, p)
+	c.Use(func(next Handler) Handler {
+		return func(r *Request) (*Response, error) {
+			r.HTTP.Header.Set("X-Test", "injected")
+			return next(r)
+		}
+	})
+
+	businesses, err := c.SearchOptions(SearchLocation("Delft"))
+	if err != nil || businesses.Total != 1 {
+		t.Fatalf("Expected the search to succeed with the valid key, got '%v', '%v'", businesses, err)
+	}
+
+	if len(used) != 3 || used[2] != "valid" {
+		t.Errorf("Expected the request to be signed again with every key, got '%v'", used)
+	}
+
+	if stats := c.Stats(); stats.Retries != 2 || stats.Requests != 1 {
+		t.Errorf("Expected a single request with 2 retries, got '%v'", stats)
+	}
+
+	//the failed keys remain quarantined
+	used = nil
+	if _, err = c.SearchOptions(SearchLocation("Delft")); err != nil || len(used) != 1 || p.Available() != 1 {
+		t.Errorf("Expected the quarantined keys to be skipped, got '%v', '%v'", used, err)
+	}
+
+	//the failure of the last key is reported when no key is left
+	p, _ = NewCredentialPool(PoolRoundRobin, time.Hour, testPoolCredentials("limited")...)
+	c = NewFromCredentialPool(server.URL, p)
+	c.Use(func(next Handler) Handler {
+		return func(r *Request) (*Response, error) {
+			r.HTTP.Header.Set("X-Test", "injected")
+			return next(r)
+		}
+	})
+
+	if _, err = c.SearchOptions(SearchLocation("Delft")); err == nil {
+		t.Errorf("Expected the search to fail when every key is rate limited")
+	}
+
+	if _, err = c.SearchOptions(SearchLocation("Delft")); err == nil {
+		t.Errorf("Expected the search to fail when every key is quarantined")
+	}
+}
@@ -0,0 +1,124 @@
+package yelp
+
+import (
+	"math"
+	"sort"
+)
+
+//The Scorer interface is implemented by all types that can score a business
+//for ranking through Rank(...). A higher score means a better business. The
+//scores of the provided scorers are roughly between 0 and 1, such that they
+//can be combined with comparable weights.
+type Scorer interface {
+	Score(*Business) float64
+}
+
+//ScorerFunc allows an ordinary function to be used as a Scorer.
+type ScorerFunc func(*Business) float64
+
+func (f ScorerFunc) Score(b *Business) float64 {
+	return f(b)
+}
+
+//BayesianRating is a Scorer adjusting the rating of a business by its number of
+//reviews. A business is considered to have Weight additional reviews rated at
+//Mean, such that a few five star reviews do not outrank many four star
+//reviews. The score is the adjusted rating divided by five.
+type BayesianRating struct {
+	Mean   float64
+	Weight float64
+}
+
+//NewBayesianRating creates a BayesianRating using the review-weighted mean
+//rating of the provided businesses as the prior mean, and the provided weight.
+func NewBayesianRating(businesses []*Business, weight float64) BayesianRating {
+	ratings, reviews := 0.0, 0.0
+
+	for _, v := range businesses {
+		ratings += v.Rating * float64(v.ReviewCount)
+		reviews += float64(v.ReviewCount)
+	}
+
+	if reviews == 0 {
+		return BayesianRating{0, weight}
+	}
+
+	return BayesianRating{ratings / reviews, weight}
+}
+
+func (br BayesianRating) Score(b *Business) float64 {
+	reviews := float64(b.ReviewCount)
+
+	if reviews+br.Weight == 0 {
+		return 0
+	}
+
+	return (br.Weight*br.Mean + reviews*b.Rating) / (br.Weight + reviews) / 5
+}
+
+//DistanceDecay is a Scorer favouring nearby businesses. The score halves for
+//every HalfDistance meters the business is away from the searched location.
+type DistanceDecay struct {
+	HalfDistance float64
+}
+
+func (dd DistanceDecay) Score(b *Business) float64 {
+	if dd.HalfDistance <= 0 {
+		return 0
+	}
+
+	return math.Exp2(-b.Distance / dd.HalfDistance)
+}
+
+//CategoryBoost is a Scorer scoring businesses by the categories they belong to.
+//It maps category aliases to a score, a business receives the highest score of
+//all its categories, or zero when none of them is listed.
+type CategoryBoost map[string]float64
+
+func (cb CategoryBoost) Score(b *Business) float64 {
+	score, found := 0.0, false
+
+	for _, v := range b.Categories {
+		if boost, ok := cb[v.Alias]; ok && (!found || boost > score) {
+			score, found = boost, true
+		}
+	}
+
+	return score
+}
+
+//The WeightedScorer structure pairs a Scorer with the weight of its score in
+//the combined score calculated by Rank(...).
+type WeightedScorer struct {
+	Scorer Scorer
+	Weight float64
+}
+
+//The RankedBusiness structure contains a business together with its combined
+//score.
+type RankedBusiness struct {
+	*Business
+	Score float64
+}
+
+//Rank scores the provided businesses with the weighted sum of the provided
+//scorers and returns them ordered from highest to lowest score. Businesses with
+//equal scores keep their original relative position. The provided slice is not
+//modified.
+func Rank(businesses []*Business, scorers ...WeightedScorer) []RankedBusiness {
+	ranked := make([]RankedBusiness, len(businesses))
+
+	for i, v := range businesses {
+		ranked[i].Business = v
+
+		for _, w := range scorers {
+			ranked[i].Score += w.Weight * w.Scorer.Score(v)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	return ranked
+}
@@ -0,0 +1,26 @@
+package yelp
+
+import (
+	"testing"
+)
+
+func TestRankBayesian(t *testing.T) {
+	few := &Business{ID: "few", Rating: 5, ReviewCount: 3, Distance: 100}
+	many := &Business{ID: "many", Rating: 4, ReviewCount: 1000, Distance: 2000}
+	businesses := []*Business{few, many}
+
+	ranked := Rank(businesses, WeightedScorer{BayesianRating{3.5, 50}, 1})
+	if ranked[0].Business != many {
+		t.Errorf("Expected many four star reviews to outrank a few five star reviews, got order '%s', '%s'", ranked[0].ID, ranked[1].ID)
+	}
+
+	//a strong distance preference should reverse the order again
+	ranked = Rank(businesses, WeightedScorer{BayesianRating{3.5, 50}, 1}, WeightedScorer{DistanceDecay{500}, 2})
+	if ranked[0].Business != few || ranked[0].Score <= ranked[1].Score {
+		t.Errorf("Expected the nearby business to rank first when weighting distance, got order '%s', '%s'", ranked[0].ID, ranked[1].ID)
+	}
+
+	if businesses[0] != few {
+		t.Errorf("Expected the ranked slice not to be modified")
+	}
+}
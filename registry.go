@@ -0,0 +1,178 @@
+package yelp
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//searchMaxOptions is the number of search options that fit in the searchBitMask
+const searchMaxOptions = 64
+
+//The searchRegistry contains the names of all search options, indexed by the
+//position of their bit in the searchBitMask, and maps the names of the query
+//elements added by the search options to the bit of the option they belong to.
+//All location elements belong to a single option, as only one location may be
+//specified. The registry starts with the options of this package and is
+//extended through RegisterSearchOption(...)
+var searchRegistry = struct {
+	lock  sync.RWMutex
+	names []string
+	keys  map[string]searchBitMask
+}{
+	names: []string{"term", "limit", "offset", "sort", "category", "radius", "deals",
+		"location", "country_code", "language", "language_filter", "action_links"},
+	keys: map[string]searchBitMask{
+		searchTermKey:            searchBitMaskTerm,
+		searchLimitKey:           searchBitMaskLimit,
+		searchOffsetKey:          searchBitMaskOffset,
+		searchSortKey:            searchBitMaskSort,
+		searchCategoryKey:        searchBitMaskCategory,
+		searchRadiusKey:          searchBitMaskRadius,
+		searchDealsKey:           searchBitMaskDeals,
+		searchLocationKey:        searchBitMaskLocation,
+		searchCoordinatesKey:     searchBitMaskLocation,
+		searchCoordinatesHintKey: searchBitMaskLocation,
+		searchBoundsKey:          searchBitMaskLocation,
+		searchCountryCodeKey:     searchBitMaskCountryCode,
+		searchLanguageKey:        searchBitMaskLanguage,
+		searchLanguageFilterKey:  searchBitMaskLanguageFilter,
+		searchActionLinksKey:     searchBitMaskActionLinks,
+	},
+}
+
+//searchKeyMask returns the bit of the search option the query element with the
+//provided name belongs to, or zero when it does not belong to any option
+func searchKeyMask(name string) searchBitMask {
+	searchRegistry.lock.RLock()
+	defer searchRegistry.lock.RUnlock()
+
+	return searchRegistry.keys[name]
+}
+
+//searchOptionNames returns the names of all registered search options, indexed
+//by the position of their bit in the searchBitMask
+func searchOptionNames() []string {
+	searchRegistry.lock.RLock()
+	defer searchRegistry.lock.RUnlock()
+
+	return append([]string(nil), searchRegistry.names...)
+}
+
+//The SearchOptionSlot structure is an exclusive slot in a SearchQuery, claimed
+//by a search option that is not part of this package. Such an option claims its
+//slot in its Query(...) method, which fails when the option was already added
+//to the query, in the same manner the options of this package do:
+//
+//	var mySlot, _ = yelp.RegisterSearchOption("my_option", "my_parameter")
+//
+//	func (o MyOption) Query(sq *yelp.SearchQuery) error {
+//		if err := mySlot.Claim(sq); err != nil {
+//			return err
+//		}
+//
+//		sq.Append("my_parameter", string(o))
+//		return nil
+//	}
+type SearchOptionSlot struct {
+	name string
+	mask searchBitMask
+}
+
+//RegisterSearchOption registers a new search option with the provided name and
+//returns its exclusive slot. The names of the query elements the option adds
+//can be provided, such that SearchQuery.Set(...), Delete(...) and
+//SearchParam(...) treat them as part of the option. An error is returned when
+//the name or any of the query elements is already registered, or when no more
+//options can be registered.
+func RegisterSearchOption(name string, keys ...string) (SearchOptionSlot, error) {
+	searchRegistry.lock.Lock()
+	defer searchRegistry.lock.Unlock()
+
+	if name == "" {
+		return SearchOptionSlot{}, Error{ErrorTypeInvalidArgumentDefinition, "RegisterSearchOption", "The option name may not be empty"}
+	}
+
+	if containsString(searchRegistry.names, name) {
+		return SearchOptionSlot{}, Error{ErrorTypeInvalidArgumentRepetition, "RegisterSearchOption",
+			fmt.Sprintf("Option '%s' is already registered", name)}
+	}
+
+	if len(searchRegistry.names) >= searchMaxOptions {
+		return SearchOptionSlot{}, Error{ErrorTypeInvalidArgumentDefinition, "RegisterSearchOption",
+			fmt.Sprintf("No more than %d options can be registered", searchMaxOptions)}
+	}
+
+	for _, v := range keys {
+		if _, ok := searchRegistry.keys[v]; ok {
+			return SearchOptionSlot{}, Error{ErrorTypeInvalidArgumentRepetition, "RegisterSearchOption",
+				fmt.Sprintf("Query element '%s' already belongs to another option", v)}
+		}
+	}
+
+	slot := SearchOptionSlot{name, 1 << uint(len(searchRegistry.names))}
+	searchRegistry.names = append(searchRegistry.names, name)
+
+	for _, v := range keys {
+		searchRegistry.keys[v] = slot.mask
+	}
+
+	return slot, nil
+}
+
+//Name returns the name with which the option was registered.
+func (s SearchOptionSlot) Name() string {
+	return s.name
+}
+
+//Claim marks the option as set in the query. An error is returned when the
+//option was already set, or when the slot was not registered.
+func (s SearchOptionSlot) Claim(sq *SearchQuery) error {
+	if s.mask == 0 {
+		return Error{ErrorTypeInvalidArgumentDefinition, "SearchOptionSlot", "Claiming an unregistered option"}
+	}
+
+	if sq.mask&s.mask != 0 {
+		return Error{ErrorTypeInvalidArgumentRepetition, s.name, "Attempting to set option a second time"}
+	}
+
+	sq.mask |= s.mask
+	return nil
+}
+
+//The searchParam structure is the search option returned by SearchParam(...)
+type searchParam struct {
+	name  string
+	value string
+}
+
+//SearchParam returns a search option adding an arbitrary query element, for
+//parameters of the Yelp API that are not supported by dedicated options. Adding
+//an element with the same name twice fails, as does adding an element that
+//belongs to an option that is already set (e.g. "location" after
+//SearchCoordinates). The OAuth elements can not be added.
+func SearchParam(name, value string) SearchQuerier {
+	return searchParam{name, value}
+}
+
+func (sp searchParam) Query(sq *SearchQuery) error {
+	//make sure the name is valid
+	if sp.name == "" || strings.HasPrefix(sp.name, "oauth_") {
+		return Error{ErrorTypeInvalidArgumentDefinition, "SearchParam", fmt.Sprintf("Invalid parameter name: '%s'", sp.name)}
+	}
+
+	//make sure neither the parameter nor its option has been set already. The
+	//cll element accompanies the location and does not set it by itself
+	mask := claimMask(sp.name)
+
+	if sq.Has(sp.name) || sq.mask&mask != 0 {
+		return Error{ErrorTypeInvalidArgumentRepetition, "SearchParam",
+			fmt.Sprintf("Attempting to set parameter '%s' a second time", sp.name)}
+	}
+
+	//add the query, update the mask and return
+	sq.Append(sp.name, sp.value)
+
+	sq.mask |= mask
+	return nil
+}
@@ -0,0 +1,88 @@
+package yelp
+
+import (
+	"testing"
+)
+
+//testSlot is registered once, as the registry is shared by all tests
+var testSlot, testSlotErr = RegisterSearchOption("test_option", "test_parameter")
+
+//testOption is a search option that is not part of the package
+type testOption string
+
+func (o testOption) Query(sq *SearchQuery) error {
+	if err := testSlot.Claim(sq); err != nil {
+		return err
+	}
+
+	sq.Append("test_parameter", string(o))
+	return nil
+}
+
+func TestRegisterSearchOption(t *testing.T) {
+	if testSlotErr != nil || testSlot.Name() != "test_option" {
+		t.Fatalf("Failed to register the test option: %v", testSlotErr)
+	}
+
+	if _, err := RegisterSearchOption("test_option"); err == nil {
+		t.Errorf("Expected registering an option twice to fail")
+	}
+
+	if _, err := RegisterSearchOption("test_other", "location"); err == nil {
+		t.Errorf("Expected registering an element of another option to fail")
+	}
+
+	var q SearchQuery
+	if err := testOption("a").Query(&q); err != nil || !q.HasOption("test_option") {
+		t.Errorf("Expected the registered option to be set, got '%v'", err)
+	}
+
+	if testOption("b").Query(&q) == nil || SearchParam("test_parameter", "b").Query(&q) == nil {
+		t.Errorf("Expected setting the registered option twice to fail")
+	}
+
+	q.Delete("test_parameter")
+	if testOption("b").Query(&q) != nil {
+		t.Errorf("Expected the registered option to be settable after deleting its element")
+	}
+
+	if (SearchOptionSlot{}).Claim(&q) == nil {
+		t.Errorf("Expected claiming an unregistered slot to fail")
+	}
+}
+
+func TestSearchParam(t *testing.T) {
+	var q SearchQuery
+	options := []SearchQuerier{SearchLocation("Delft"), SearchParam("cll", "52,4.35"), SearchParam("attrs", "GoodForKids")}
+
+	for _, v := range options {
+		if err := v.Query(&q); err != nil {
+			t.Errorf("Expected adding '%v' to succeed, got '%v'", v, err)
+		}
+	}
+
+	if q.String() != "location=Delft&cll=52,4.35&attrs=GoodForKids" {
+		t.Errorf("Expected the parameters to be added, got '%s'", q.String())
+	}
+
+	for _, v := range []SearchQuerier{SearchParam("attrs", "x"), SearchParam("ll", "52,4"), SearchParam("cll", "52,4"),
+		SearchParam("", "x"), SearchParam("oauth_token", "x")} {
+		if v.Query(&q) == nil {
+			t.Errorf("Expected adding '%v' to fail", v)
+		}
+	}
+
+	//a known parameter sets its option
+	q = SearchQuery{}
+	SearchParam("limit", "5").Query(&q)
+	if SearchLimit(5).Query(&q) == nil {
+		t.Errorf("Expected the limit to be set by the parameter")
+	}
+
+	//the hint does not set the location
+	q = SearchQuery{}
+	SearchParam("cll", "52,4.35").Query(&q)
+	if SearchLocation("Delft").Query(&q) != nil || q.String() != "cll=52,4.35&location=Delft" {
+		t.Errorf("Expected the location to be settable after the hint, got '%s'", q.String())
+	}
+}
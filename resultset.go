@@ -0,0 +1,187 @@
+package yelp
+
+import (
+	"strings"
+	"unicode"
+)
+
+//resultMatchDistance is the maximum distance in meters between two businesses
+//without an ID that have the same name for them to be considered equal
+const resultMatchDistance float64 = 50
+
+//The ResultEntry structure is a single business in a ResultSet together with
+//the sources (e.g. descriptions of the searches) that returned it.
+type ResultEntry struct {
+	Business *Business
+	Sources  []string
+}
+
+//The ResultSet structure merges the businesses returned by multiple searches
+//into a single set without duplicates. Businesses are matched by their Yelp
+//ID. When one of the businesses has no ID they are matched on their name
+//together with either their address or their coordinates. A ResultSet should
+//be created through NewResultSet().
+type ResultSet struct {
+	entries []*ResultEntry
+	ids     map[string]*ResultEntry
+}
+
+//NewResultSet creates a new empty ResultSet.
+func NewResultSet() *ResultSet {
+	return &ResultSet{ids: make(map[string]*ResultEntry)}
+}
+
+//Add merges the provided businesses into the result set, recording the
+//provided source for each of them. A business that is already in the set keeps
+//the data of its first occurrence.
+func (rs *ResultSet) Add(source string, businesses *Businesses) {
+	if businesses == nil {
+		return
+	}
+
+	for _, v := range businesses.Businesses {
+		entry := rs.find(v)
+
+		if entry == nil {
+			entry = &ResultEntry{Business: v}
+			rs.entries = append(rs.entries, entry)
+		}
+
+		//also record the ID when the entry was matched on its name, such that
+		//later occurrences are matched by their ID
+		if _, ok := rs.ids[v.ID]; v.ID != "" && !ok {
+			rs.ids[v.ID] = entry
+		}
+
+		if !containsString(entry.Sources, source) {
+			entry.Sources = append(entry.Sources, source)
+		}
+	}
+}
+
+//Len returns the number of distinct businesses in the result set.
+func (rs *ResultSet) Len() int {
+	return len(rs.entries)
+}
+
+//Entries returns all businesses in the result set, together with their
+//sources, in the order in which they were first added.
+func (rs *ResultSet) Entries() []ResultEntry {
+	entries := make([]ResultEntry, len(rs.entries))
+
+	for i, v := range rs.entries {
+		entries[i] = ResultEntry{v.Business, append([]string(nil), v.Sources...)}
+	}
+
+	return entries
+}
+
+//Sources returns the sources that returned the provided business, or nil when
+//the business is not in the result set.
+func (rs *ResultSet) Sources(b *Business) []string {
+	entry := rs.find(b)
+
+	if entry == nil {
+		return nil
+	}
+
+	return append([]string(nil), entry.Sources...)
+}
+
+//Businesses returns the merged businesses. The Total is the number of distinct
+//businesses and the Region is recomputed to span the positions of all of them,
+//crossing the antimeridian when that results in a smaller region.
+func (rs *ResultSet) Businesses() *Businesses {
+	result := &Businesses{Businesses: make([]*Business, len(rs.entries)), Total: len(rs.entries)}
+	var positions []Coordinates
+
+	for i, v := range rs.entries {
+		result.Businesses[i] = v.Business
+
+		if position, ok := businessPosition(v.Business); ok {
+			positions = append(positions, position)
+		}
+	}
+
+	if len(positions) != 0 {
+		region := boundsOf(positions).Region()
+		result.Region = &region
+	}
+
+	return result
+}
+
+//find returns the entry matching the provided business, or nil when the
+//business is not in the result set
+func (rs *ResultSet) find(b *Business) *ResultEntry {
+	if b.ID != "" {
+		if entry, ok := rs.ids[b.ID]; ok {
+			return entry
+		}
+	}
+
+	for _, v := range rs.entries {
+		if (b.ID == "" || v.Business.ID == "") && similarBusinesses(v.Business, b) {
+			return v
+		}
+	}
+
+	return nil
+}
+
+//similarBusinesses returns true when two businesses have the same normalized
+//name and either the same normalized address or nearby coordinates
+func similarBusinesses(a, b *Business) bool {
+	if normalizeName(a.Name) != normalizeName(b.Name) {
+		return false
+	}
+
+	if a.Location == nil || b.Location == nil {
+		return false
+	}
+
+	if len(a.Location.Address) != 0 && len(b.Location.Address) != 0 &&
+		normalizeName(a.Location.Address[0]) == normalizeName(b.Location.Address[0]) {
+		return true
+	}
+
+	positionA, okA := businessPosition(a)
+	positionB, okB := businessPosition(b)
+
+	return okA && okB && positionA.DistanceTo(positionB) <= resultMatchDistance
+}
+
+//businessPosition returns the coordinates of a business. The boolean return
+//value is false when the business has no known coordinates
+func businessPosition(b *Business) (Coordinates, bool) {
+	if b.Location == nil || (b.Location.Position == Coordinates{}) {
+		return Coordinates{}, false
+	}
+
+	return b.Location.Position, true
+}
+
+//normalizeName converts a name or address to lower case and strips everything
+//but letters and digits, such that small differences in spelling are ignored
+func normalizeName(name string) string {
+	var builder strings.Builder
+
+	for _, v := range strings.ToLower(name) {
+		if unicode.IsLetter(v) || unicode.IsDigit(v) {
+			builder.WriteRune(v)
+		}
+	}
+
+	return builder.String()
+}
+
+//containsString returns true when the provided list contains the value
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
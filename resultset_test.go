@@ -0,0 +1,57 @@
+package yelp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestResultSetMerge(t *testing.T) {
+	cafe := &Business{ID: "cafe-delft", Name: "Cafe", Location: &BusinessLocation{Position: Coordinates{52.01, 4.35}}}
+	bar := &Business{Name: "De Bar", Location: &BusinessLocation{Address: []string{"Markt 1"}, Position: Coordinates{52.0, 4.36}}}
+	barAgain := &Business{Name: "de bar!", Location: &BusinessLocation{Address: []string{"Markt 1"}}}
+	barNearby := &Business{ID: "de-bar", Name: "De Bar", Location: &BusinessLocation{Position: Coordinates{52.0001, 4.3601}}}
+	other := &Business{Name: "De Bar", Location: &BusinessLocation{Position: Coordinates{52.1, 4.4}}}
+
+	rs := NewResultSet()
+	rs.Add("bars", &Businesses{Businesses: []*Business{cafe, bar}})
+	rs.Add("delft", &Businesses{Businesses: []*Business{cafe, barAgain, barNearby, other}})
+
+	if rs.Len() != 3 {
+		t.Fatalf("Expected 3 distinct businesses, got %d", rs.Len())
+	}
+
+	if sources := rs.Sources(barNearby); !reflect.DeepEqual(sources, []string{"bars", "delft"}) {
+		t.Errorf("Expected the bar to be returned by both searches, got '%v'", sources)
+	}
+
+	if sources := rs.Sources(other); !reflect.DeepEqual(sources, []string{"delft"}) {
+		t.Errorf("Expected the distant bar to be returned by a single search, got '%v'", sources)
+	}
+
+	merged := rs.Businesses()
+	if merged.Total != 3 || merged.Region == nil ||
+		math.Abs(merged.Region.Span.Latitude-0.1) > 1e-9 || math.Abs(merged.Region.Center.Longitude-4.375) > 1e-9 {
+		t.Errorf("Expected 3 businesses in a region spanning all of them, got %d in '%v'", merged.Total, merged.Region)
+	}
+
+	//the ID of a business matched on its name is matched afterwards
+	renamed := &Business{ID: "de-bar", Name: "Bar De Markt", Location: &BusinessLocation{Position: Coordinates{52.0002, 4.3602}}}
+	rs.Add("renamed", &Businesses{Businesses: []*Business{renamed}})
+
+	if rs.Len() != 3 || !reflect.DeepEqual(rs.Sources(bar), []string{"bars", "delft", "renamed"}) {
+		t.Errorf("Expected the renamed bar to be matched by its ID, got %d businesses", rs.Len())
+	}
+}
+
+func TestResultSetRegionAntimeridian(t *testing.T) {
+	rs := NewResultSet()
+	rs.Add("fiji", &Businesses{Businesses: []*Business{
+		{ID: "a", Location: &BusinessLocation{Position: Coordinates{-17, 179.9}}},
+		{ID: "b", Location: &BusinessLocation{Position: Coordinates{-16, -179.9}}}}})
+
+	region := rs.Businesses().Region
+	if region == nil || math.Abs(region.Span.Longitude-0.2) > 1e-9 || math.Abs(math.Abs(region.Center.Longitude)-180) > 1e-9 {
+		t.Errorf("Expected a region spanning the antimeridian, got '%v'", region)
+	}
+}
@@ -0,0 +1,246 @@
+package yelp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+//The SavedSearch structure is the document format in which a set of search
+//options can be stored, for example in a JSON file containing all recurring
+//searches of a project. Every field is optional, fields that are not specified
+//will not result in a search option. A SavedSearch can be converted into search
+//options through the Options() method, and be created from existing search
+//options through the NewSavedSearch(...) function. Saved searches are read and
+//written as JSON only. YAML is not supported, as it would require a dependency
+//outside of the standard library.
+type SavedSearch struct {
+	Name        string        `json:"name,omitempty"`
+	Location    string        `json:"location,omitempty"`
+	Coordinates *Coordinates  `json:"coordinates,omitempty"`
+	Bounds      *SearchBounds `json:"bounds,omitempty"`
+	Terms       []string      `json:"terms,omitempty"`
+	Categories  []string      `json:"categories,omitempty"`
+	Radius      *int          `json:"radius,omitempty"`
+	Sort        string        `json:"sort,omitempty"`
+	Limit       *int          `json:"limit,omitempty"`
+	Offset      *int          `json:"offset,omitempty"`
+	Deals       *bool         `json:"deals,omitempty"`
+
+	CountryCode    string `json:"country_code,omitempty"`
+	Language       string `json:"language,omitempty"`
+	LanguageFilter *bool  `json:"language_filter,omitempty"`
+	ActionLinks    *bool  `json:"action_links,omitempty"`
+}
+
+//Options converts the saved search into a list of search options that can be
+//passed to Client.SearchOptions(...). All options are validated by querying
+//them into an empty SearchQuery, such that any error is reported when loading
+//the saved search rather than when performing the search.
+func (s SavedSearch) Options() ([]SearchQuerier, error) {
+	var options []SearchQuerier
+
+	//determine the location, which can be specified in several ways
+	switch {
+	case s.Bounds != nil:
+		if s.Location != "" || s.Coordinates != nil {
+			return nil, Error{ErrorTypeInvalidArgumentRepetition, "SavedSearch", "Bounds cannot be combined with a location or coordinates"}
+		}
+
+		options = append(options, *s.Bounds)
+	case s.Location != "" && s.Coordinates != nil:
+		options = append(options, SearchLocationCoordinates{s.Location, s.Coordinates.Latitude, s.Coordinates.Longitude})
+	case s.Location != "":
+		options = append(options, SearchLocation(s.Location))
+	case s.Coordinates != nil:
+		options = append(options, SearchCoordinates{s.Coordinates.Latitude, s.Coordinates.Longitude})
+	}
+
+	if len(s.Terms) != 0 {
+		//copy the terms, the SearchTerms option should not share its memory
+		//with the saved search
+		options = append(options, SearchTerms(append([]string(nil), s.Terms...)))
+	}
+
+	if len(s.Categories) != 0 {
+		categories := make(SearchCategories, len(s.Categories))
+
+		for i, v := range s.Categories {
+			category, ok := parseSearchCategory(v)
+
+			if !ok {
+				return nil, Error{ErrorTypeInvalidArgumentDefinition, "SavedSearch", fmt.Sprintf("Unknown search category: %s", v)}
+			}
+
+			categories[i] = category
+		}
+
+		options = append(options, categories)
+	}
+
+	if s.Radius != nil {
+		options = append(options, SearchRadius(*s.Radius))
+	}
+
+	if s.Sort != "" {
+		sort, ok := parseSearchSort(s.Sort)
+
+		if !ok {
+			return nil, Error{ErrorTypeInvalidArgumentDefinition, "SavedSearch", fmt.Sprintf("Unknown sorting method: %s", s.Sort)}
+		}
+
+		options = append(options, sort)
+	}
+
+	if s.Limit != nil {
+		options = append(options, SearchLimit(*s.Limit))
+	}
+
+	if s.Offset != nil {
+		options = append(options, SearchOffset(*s.Offset))
+	}
+
+	if s.Deals != nil {
+		options = append(options, SearchDeals(*s.Deals))
+	}
+
+	if s.CountryCode != "" {
+		options = append(options, SearchCountryCode(s.CountryCode))
+	}
+
+	if s.Language != "" {
+		options = append(options, SearchLanguage(s.Language))
+	}
+
+	if s.LanguageFilter != nil {
+		options = append(options, SearchLanguageFilter(*s.LanguageFilter))
+	}
+
+	if s.ActionLinks != nil {
+		options = append(options, SearchActionLinks(*s.ActionLinks))
+	}
+
+	//validate all options at once by creating a query from them
+	var q SearchQuery
+
+	for _, v := range options {
+		err := v.Query(&q)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+//NewSavedSearch creates a SavedSearch document with the provided name from a
+//list of search options. The options are validated in the same manner as
+//Client.SearchOptions(...) would. An error is returned when one of the options
+//is invalid or cannot be represented by a SavedSearch.
+func NewSavedSearch(name string, options ...SearchQuerier) (s SavedSearch, err error) {
+	s.Name = name
+
+	//validate the options in the same manner as a search would, before any of
+	//them is converted, as the conversion expects valid values
+	var q SearchQuery
+
+	for _, v := range options {
+		err = v.Query(&q)
+
+		if err != nil {
+			return SavedSearch{}, err
+		}
+	}
+
+	for _, v := range options {
+		switch o := v.(type) {
+		case SearchLocation:
+			s.Location = string(o)
+		case SearchCoordinates:
+			s.Coordinates = &Coordinates{o.Latitude, o.Longitude}
+		case SearchLocationCoordinates:
+			s.Location = o.Location
+			s.Coordinates = &Coordinates{o.Latitude, o.Longitude}
+		case SearchBounds:
+			bounds := o
+			s.Bounds = &bounds
+		case SearchTerms:
+			s.Terms = append([]string(nil), o...)
+		case SearchCategories:
+			s.Categories = make([]string, len(o))
+
+			for i, c := range o {
+				s.Categories[i] = c.String()
+			}
+		case SearchRadius:
+			radius := int(o)
+			s.Radius = &radius
+		case SearchSort:
+			s.Sort = searchSortNames[o]
+		case SearchLimit:
+			limit := int(o)
+			s.Limit = &limit
+		case SearchOffset:
+			offset := int(o)
+			s.Offset = &offset
+		case SearchDeals:
+			deals := bool(o)
+			s.Deals = &deals
+		case SearchCountryCode:
+			s.CountryCode = string(o)
+		case SearchLanguage:
+			s.Language = string(o)
+		case SearchLanguageFilter:
+			filter := bool(o)
+			s.LanguageFilter = &filter
+		case SearchActionLinks:
+			links := bool(o)
+			s.ActionLinks = &links
+		default:
+			return SavedSearch{}, Error{ErrorTypeInvalidArgumentDefinition, "SavedSearch", fmt.Sprintf("Search option '%T' cannot be saved", v)}
+		}
+	}
+
+	return s, nil
+}
+
+//ReadSavedSearches reads a JSON array of SavedSearch documents from the
+//provided reader. Unknown fields are considered to be an error, and every saved
+//search is validated through its Options() method.
+func ReadSavedSearches(r io.Reader) ([]SavedSearch, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var searches []SavedSearch
+	err := decoder.Decode(&searches)
+
+	if err != nil {
+		return nil, Error{ErrorTypeReadFailure, "SavedSearch", fmt.Sprintf("Failed to decode saved searches: %v", err)}
+	}
+
+	for i, v := range searches {
+		_, err = v.Options()
+
+		if err != nil {
+			return nil, Error{ErrorTypeInvalidArgumentDefinition, "SavedSearch", fmt.Sprintf("Invalid saved search %d (%s): %v", i, v.Name, err)}
+		}
+	}
+
+	return searches, nil
+}
+
+//WriteSavedSearches writes the provided SavedSearch documents to the writer as
+//an indented JSON array, such that it can be read back by ReadSavedSearches.
+func WriteSavedSearches(w io.Writer, searches []SavedSearch) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+
+	err := encoder.Encode(searches)
+
+	if err != nil {
+		return Error{ErrorTypeWriteFailure, "SavedSearch", fmt.Sprintf("Failed to encode saved searches: %v", err)}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package yelp
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSavedSearchRoundTrip(t *testing.T) {
+	options := []SearchQuerier{SearchLocation("Delft"),
+		SearchTerms([]string{"bar", "cafe"}),
+		SearchCategories([]SearchCategory{SearchCategoryBars, SearchCategoryPubFood}),
+		SearchRadius(2000),
+		SearchSort(SearchSortHighestRated),
+		SearchLimit(10),
+		SearchDeals(true)}
+
+	saved, err := NewSavedSearch("delft", options...)
+	if err != nil {
+		t.Fatalf("Expected creating a saved search to succeed: %v", err)
+	}
+
+	//write the search and read it back again
+	var buffer bytes.Buffer
+	err = WriteSavedSearches(&buffer, []SavedSearch{saved})
+	if err != nil {
+		t.Fatalf("Expected writing saved searches to succeed: %v", err)
+	}
+
+	searches, err := ReadSavedSearches(&buffer)
+	if err != nil {
+		t.Fatalf("Expected reading saved searches to succeed: %v", err)
+	}
+
+	if len(searches) != 1 {
+		t.Fatalf("Expected a single saved search, got %d", len(searches))
+	}
+
+	loaded, err := searches[0].Options()
+	if err != nil {
+		t.Fatalf("Expected converting the saved search to options to succeed: %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded, options) {
+		t.Errorf("Expected loaded options '%v' to equal '%v'", loaded, options)
+	}
+}
+
+func TestSavedSearchInvalid(t *testing.T) {
+	documents := []string{`[{"location": "Delft", "unknown": 1}]`,
+		`[{"categories": ["nonexisting"]}]`,
+		`[{"sort": "nonexisting"}]`,
+		`[{"limit": 100}]`,
+		`[{"radius": -1}]`,
+		`[{"location": "Delft", "bounds": {"sw_latitude": 0, "sw_longitude": 0, "ne_latitude": 1, "ne_longitude": 1}}]`}
+
+	for _, v := range documents {
+		_, err := ReadSavedSearches(strings.NewReader(v))
+		if err == nil {
+			t.Errorf("Expected reading saved search '%s' to fail", v)
+		}
+	}
+}
+
+func TestNewSavedSearchInvalid(t *testing.T) {
+	invalid := [][]SearchQuerier{{SearchSort(7)},
+		{SearchSort(-1)},
+		{SearchCategories([]SearchCategory{SearchCategory(99)})},
+		{SearchCategories([]SearchCategory{SearchCategoryBars, SearchCategory(-1)})},
+		{SearchLocation("Delft"), SearchCoordinates{0, 0}},
+		{SearchParam("attrs", "x")}}
+
+	for _, v := range invalid {
+		if _, err := NewSavedSearch("invalid", v...); err == nil {
+			t.Errorf("Expected creating a saved search from '%v' to fail", v)
+		}
+	}
+}
@@ -23,12 +23,16 @@ const (
 	searchCoordinatesKey     = "ll"
 	searchCoordinatesHintKey = "cll"
 	searchBoundsKey          = "bounds"
+	searchCountryCodeKey     = "cc"
+	searchLanguageKey        = "lang"
+	searchLanguageFilterKey  = "lang_filter"
+	searchActionLinksKey     = "actionlinks"
 )
 
 //The Yelp query bitmask. This bitmask is used when asking the client to perform
 //a search query on the basis of specified options to make sure options do not
 //appear twice in the total query.
-type searchBitMask uint8
+type searchBitMask uint64
 
 //The searchBitMaskXXX terms constants are the binary masks that are used by the
 //SearchQuery structure to keep track of which query elements have already been
@@ -44,8 +48,12 @@ const (
 	searchBitMaskRadius
 	searchBitMaskDeals
 	searchBitMaskLocation
-	//Note: 8 values are specified, when this list is extended please update the
-	//searchBitMask to use a larger number of bits
+	searchBitMaskCountryCode
+	searchBitMaskLanguage
+	searchBitMaskLanguageFilter
+	searchBitMaskActionLinks
+	//Note: 12 values are specified, the remaining bits are assigned to the
+	//options registered through RegisterSearchOption(...)
 )
 
 //The searchQueryElement represents an element in a SearchQuery. It contains a
@@ -58,7 +66,12 @@ type searchQueryElement struct {
 //The SearchQuery structure contains a list of search query elements and a bit
 //mask. The bit mask is used when the Yelp client is creating a search query
 //from specified options (implementing the SearchQuerier interface) with the
-//purpose of not performing the same search twice
+//purpose of not performing the same search twice.
+//
+//A SearchQuery is safe for concurrent use by multiple goroutines as long as it
+//is not modified through Append(...), Sort() or a SearchQuerier. The With(...)
+//and Without(...) methods return a modified copy and leave the original query
+//untouched.
 type SearchQuery struct {
 	queries []searchQueryElement
 	mask    searchBitMask
@@ -105,12 +118,172 @@ func (q *SearchQuery) String() string {
 	return buffer.String()
 }
 
+//pairs returns all query elements as "name=value" pairs, encoded using the
+//provided encoding and sorted by their encoded name and value as required for
+//an OAuth signature base string
+func (q *SearchQuery) pairs(e Encoding) []string {
+	elements := make([]searchQueryElement, len(q.queries))
+
+	for i, v := range q.queries {
+		if e == EncodingYelpLegacy {
+			elements[i] = searchQueryElement{v.Name, strings.Replace(v.Value, " ", "+", -1)}
+		} else {
+			elements[i] = searchQueryElement{percentEncode(v.Name), percentEncode(v.Value)}
+		}
+	}
+
+	sort.SliceStable(elements, func(i, j int) bool {
+		if elements[i].Name != elements[j].Name {
+			return elements[i].Name < elements[j].Name
+		}
+
+		return elements[i].Value < elements[j].Value
+	})
+
+	pairs := make([]string, len(elements))
+
+	for i, v := range elements {
+		pairs[i] = v.Name + "=" + v.Value
+	}
+
+	return pairs
+}
+
+//encode returns the sorted query elements, encoded using the provided encoding,
+//in the form used by the query string of an URL
+func (q *SearchQuery) encode(e Encoding) string {
+	return strings.Join(q.pairs(e), "&")
+}
+
 //Append simply addes a new query element, defined by its name and value, to
 //the SearchQuery.
 func (q *SearchQuery) Append(name, value string) {
 	q.queries = append(q.queries, searchQueryElement{name, value})
 }
 
+//clone returns a copy of the query that does not share any memory with the
+//original query
+func (q *SearchQuery) clone() SearchQuery {
+	return SearchQuery{append([]searchQueryElement(nil), q.queries...), q.mask}
+}
+
+//claimMask returns the bit of the search option that is set by adding the
+//query element with the provided name. The cll hint only accompanies a
+//location, so it does not set the location option by itself
+func claimMask(name string) searchBitMask {
+	if name == searchCoordinatesHintKey {
+		return 0
+	}
+
+	return searchKeyMask(name)
+}
+
+//With returns a copy of the query with the provided element appended to it.
+//The original query is not modified. When the element belongs to a search
+//option, for example "location", that option is considered to be set in the
+//returned query. The "cll" element does not set the location option.
+func (q SearchQuery) With(name, value string) SearchQuery {
+	result := q.clone()
+	result.Append(name, value)
+	result.mask |= claimMask(name)
+
+	return result
+}
+
+//Without returns a copy of the query without any of the elements with the
+//provided names. The original query is not modified. A search option is no
+//longer considered to be set in the returned query once all of its elements
+//have been removed, where a remaining "cll" element does not keep the location
+//set.
+func (q SearchQuery) Without(names ...string) SearchQuery {
+	result := SearchQuery{make([]searchQueryElement, 0, len(q.queries)), q.mask}
+	var removed, remaining searchBitMask
+
+	for _, v := range q.queries {
+		if containsString(names, v.Name) {
+			removed |= claimMask(v.Name)
+		} else {
+			result.queries = append(result.queries, v)
+			remaining |= claimMask(v.Name)
+		}
+	}
+
+	result.mask &^= removed &^ remaining
+	return result
+}
+
+//Get returns the value of the first query element with the provided name. The
+//boolean return value is false when no such element exists.
+func (q SearchQuery) Get(name string) (string, bool) {
+	for _, v := range q.queries {
+		if v.Name == name {
+			return v.Value, true
+		}
+	}
+
+	return "", false
+}
+
+//Has returns true when the query contains an element with the provided name.
+func (q SearchQuery) Has(name string) bool {
+	_, ok := q.Get(name)
+	return ok
+}
+
+//Set replaces all query elements of the option the provided element belongs
+//to by the provided element, such that the option is set exactly once and can
+//not be added again through a SearchQuerier. As there can only be a single
+//location, setting e.g. the "location" element removes the "ll", "cll" and
+//"bounds" elements. The "cll" element is the exception, it only replaces an
+//existing "cll" element as it accompanies the "location" element, and does not
+//set the location option by itself. Elements that do not belong to an option
+//only replace elements with the same name.
+func (q *SearchQuery) Set(name, value string) {
+	mask := claimMask(name)
+	result := make([]searchQueryElement, 0, len(q.queries)+1)
+
+	for _, v := range q.queries {
+		if v.Name == name || (mask != 0 && searchKeyMask(v.Name) == mask) {
+			continue
+		}
+
+		result = append(result, v)
+	}
+
+	q.queries = append(result, searchQueryElement{name, value})
+	q.mask |= mask
+}
+
+//Delete removes all query elements with the provided name. A search option is
+//no longer considered to be set once all of its elements have been removed.
+func (q *SearchQuery) Delete(name string) {
+	*q = q.Without(name)
+}
+
+//Options returns the names of the search options that are set in the query,
+//either through a SearchQuerier or through Set(...), such that they can not
+//be added again. The names are "term", "limit", "offset", "sort", "category",
+//"radius", "deals", "location", "country_code", "language", "language_filter"
+//and "action_links", or the names of options registered through
+//RegisterSearchOption(...).
+func (q SearchQuery) Options() []string {
+	var options []string
+
+	for i, v := range searchOptionNames() {
+		if q.mask&(1<<uint(i)) != 0 {
+			options = append(options, v)
+		}
+	}
+
+	return options
+}
+
+//HasOption returns true when the search option with the provided name, as
+//returned by Options(), is set in the query.
+func (q SearchQuery) HasOption(option string) bool {
+	return containsString(q.Options(), option)
+}
+
 //The SearchQuerier interface provides a method for search options to translate
 //their option into a search query element. The method to retrieve the query
 //accepts a pointer to a query which will be modified (c-style)
@@ -158,8 +331,8 @@ func (sl SearchLocation) Query(sq *SearchQuery) error {
 		return Error{ErrorTypeInvalidArgumentRepetition, "SearchLocation", "Attempting to set location for a second time"}
 	}
 
-	//ensure there are no spaces in the location name and add the result to the query
-	sq.Append(searchLocationKey, strings.Replace(string(sl), " ", "+", -1))
+	//add the location name to the query, it is encoded when the query is sent
+	sq.Append(searchLocationKey, string(sl))
 
 	//modify the mask and return
 	sq.mask |= searchBitMaskLocation
@@ -182,13 +355,13 @@ func (slc SearchLocationCoordinates) Query(sq *SearchQuery) error {
 		return Error{ErrorTypeInvalidArgumentRepetition, "SearchLocationCoordinates", "Attempting to set location for a second time"}
 	}
 
-	//ensure there are no spaces in the location name
-	sq.Append(searchLocationKey, strings.Replace(slc.Location, " ", "+", -1))
+	//add the location name to the query, it is encoded when the query is sent
+	sq.Append(searchLocationKey, slc.Location)
 
 	//ensure the provided latitude and longitude are correct
 	if validLatitudeLongitude(slc.Latitude, slc.Longitude) == false {
 		return Error{ErrorTypeInvalidArgumentDefinition, "SearchLocationCoordinates",
-			fmt.Sprintf("Invalid latitude and/or longitude: %f, $f", slc.Latitude, slc.Longitude)}
+			fmt.Sprintf("Invalid latitude and/or longitude: %f, %f", slc.Latitude, slc.Longitude)}
 	}
 
 	//convert float latitude and longitude to string
@@ -205,12 +378,14 @@ func (slc SearchLocationCoordinates) Query(sq *SearchQuery) error {
 }
 
 //SearchBounds is a search option specifying a location range in terms of a
-//bounding box created by two pairs of longitude and latitude coordinates
+//bounding box created by two pairs of longitude and latitude coordinates. The
+//box may cross the antimeridian, in which case the southwest longitude is
+//larger than the northeast longitude
 type SearchBounds struct {
-	SWLatitude  float64
-	SWLongitude float64
-	NELatitude  float64
-	NELongitude float64
+	SWLatitude  float64 `json:"sw_latitude"`
+	SWLongitude float64 `json:"sw_longitude"`
+	NELatitude  float64 `json:"ne_latitude"`
+	NELongitude float64 `json:"ne_longitude"`
 }
 
 func (sb SearchBounds) Query(sq *SearchQuery) error {
@@ -219,15 +394,10 @@ func (sb SearchBounds) Query(sq *SearchQuery) error {
 		return Error{ErrorTypeInvalidArgumentRepetition, "SearchBounds", "Attempting to set location for a second time"}
 	}
 
-	//check the validity of the arguments
-	if validLatitudeLongitude(sb.SWLatitude, sb.SWLongitude) == false {
-		return Error{ErrorTypeInvalidArgumentDefinition, "SearchBounds",
-			fmt.Sprintf("Invalid southwest latitude and/or longitude: %f, %f", sb.SWLatitude, sb.SWLongitude)}
-	}
-
-	if validLatitudeLongitude(sb.NELatitude, sb.NELongitude) == false {
-		return Error{ErrorTypeInvalidArgumentDefinition, "SearchBounds",
-			fmt.Sprintf("Invalid northeast latitude and/or longitude: %f, %f", sb.NELatitude, sb.NELongitude)}
+	//check the validity of the arguments, the southwest corner should actually
+	//be south-west of the northeast corner (possibly crossing the antimeridian)
+	if err := sb.Bounds().validate("SearchBounds"); err != nil {
+		return err
 	}
 
 	//convert float latitudes and longitudes to the required format
@@ -258,12 +428,8 @@ func (st SearchTerms) Query(sq *SearchQuery) error {
 		return Error{ErrorTypeInvalidArgumentRepetition, "SearchTerms", "Attempting to set search terms a second time"}
 	}
 
-	//replace all terms with a space by a plus-sign
-	for i, v := range st {
-		st[i] = strings.Replace(v, " ", "+", -1)
-	}
-
-	//set the terms by joining all terms with a comma
+	//set the terms by joining all terms with a comma, they are encoded when the
+	//query is sent
 	sq.Append(searchTermKey, strings.Join(st, ","))
 
 	//set the mask and return
@@ -326,6 +492,23 @@ const (
 	SearchSortHighestRated            = 2
 )
 
+//The searchSortNames array contains the textual names of the sorting methods,
+//indexed by their SearchSort value. These are used when search options are
+//stored in or read from a textual format
+var searchSortNames = [...]string{"best_matched", "distance", "highest_rated"}
+
+//parseSearchSort returns the sorting method belonging to the provided name. The
+//boolean return value is false when the name is unknown
+func parseSearchSort(name string) (SearchSort, bool) {
+	for i, v := range searchSortNames {
+		if v == name {
+			return SearchSort(i), true
+		}
+	}
+
+	return 0, false
+}
+
 func (ss SearchSort) Query(sq *SearchQuery) error {
 	//make sure the sorting method hasn't already been set
 	if sq.mask&searchBitMaskSort != 0 {
@@ -388,6 +571,18 @@ func (sc SearchCategory) String() string {
 	return searchCategoryNames[sc]
 }
 
+//parseSearchCategory returns the category belonging to the provided Yelp
+//category name. The boolean return value is false when the name is unknown
+func parseSearchCategory(name string) (SearchCategory, bool) {
+	for i, v := range searchCategoryNames {
+		if v == name {
+			return SearchCategory(i), true
+		}
+	}
+
+	return 0, false
+}
+
 //SearchCategories is a search option to tell Yelp to only return businesses
 //belonging to a certain set of categories
 type SearchCategories []SearchCategory
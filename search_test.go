@@ -1,6 +1,7 @@
 package yelp
 
 import (
+	"sync"
 	"testing"
 )
 
@@ -16,7 +17,11 @@ func TestArgumentRepetition(t *testing.T) {
 		SearchSort(SearchSortDistance),
 		SearchCategories([]SearchCategory{SearchCategoryBars}),
 		SearchRadius(20000),
-		SearchDeals(false)}
+		SearchDeals(false),
+		SearchCountryCode("NL"),
+		SearchLanguage("nl"),
+		SearchLanguageFilter(true),
+		SearchActionLinks(true)}
 
 	//first test all possible combinations of positions
 	for _, v := range listPosition {
@@ -55,3 +60,223 @@ func TestArgumentRepetition(t *testing.T) {
 		}
 	}
 }
+
+func TestLocaleValidation(t *testing.T) {
+	valid := [][]SearchQuerier{{SearchCountryCode("NL"), SearchLanguage("nl")},
+		{SearchLanguage("de"), SearchCountryCode("AT")},
+		{SearchLanguage("fr")}}
+	invalid := [][]SearchQuerier{{SearchCountryCode("XX")},
+		{SearchLanguage("xx")},
+		{SearchCountryCode("NL"), SearchLanguage("de")},
+		{SearchLanguage("ja"), SearchCountryCode("DE")}}
+
+	for _, v := range valid {
+		var q SearchQuery
+		for _, w := range v {
+			if err := w.Query(&q); err != nil {
+				t.Errorf("Expected locale options '%v' to succeed: %v", v, err)
+			}
+		}
+	}
+
+	for _, v := range invalid {
+		var q SearchQuery
+		var err error
+		for _, w := range v {
+			if err = w.Query(&q); err != nil {
+				break
+			}
+		}
+
+		if err == nil {
+			t.Errorf("Expected locale options '%v' to fail", v)
+		}
+	}
+}
+
+func TestSearchQueryWithWithout(t *testing.T) {
+	var q SearchQuery
+	SearchLocationCoordinates{"Delft", 52, 4.35}.Query(&q)
+	SearchLimit(10).Query(&q)
+
+	r := q.With("term", "bar").Without("limit", "cll")
+	if q.String() != "location=Delft&cll=52,4.35&limit=10" {
+		t.Errorf("Expected the original query to be unchanged, got '%s'", q.String())
+	}
+
+	if r.String() != "location=Delft&term=bar" {
+		t.Errorf("Expected the new query to contain the location and term, got '%s'", r.String())
+	}
+
+	//the limit may be set again, the location is still partially present
+	if SearchLimit(5).Query(&r) != nil {
+		t.Errorf("Expected the limit to be settable after removing it")
+	}
+
+	if SearchLocation("Delft").Query(&r) == nil || (SearchTerms{"bar"}).Query(&r) == nil {
+		t.Errorf("Expected the location and term to remain set")
+	}
+
+	r = q.Without("location", "cll")
+	if SearchLocation("Delft").Query(&r) != nil {
+		t.Errorf("Expected the location to be settable after removing all of its elements")
+	}
+}
+
+func TestSearchQueryConcurrentUse(t *testing.T) {
+	c := stubClient(t, func(*Request) (*Response, error) {
+		return &Response{Businesses: &Businesses{}}, nil
+	})
+
+	//leave spare capacity, which signing a copy of the query could overwrite
+	q := SearchQuery{queries: make([]searchQueryElement, 0, 32)}
+	q.Append("term", "bar")
+	q.Append("location", "Delft")
+
+	var wait sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+
+			for j := 0; j < 20; j++ {
+				if _, err := c.SearchQuery(q); err != nil {
+					t.Errorf("Expected the concurrent search to succeed, got '%v'", err)
+				}
+
+				c.DebugSignature(q)
+				q.With("limit", "5").Without("term")
+			}
+		}()
+	}
+
+	wait.Wait()
+
+	for _, v := range q.queries[len(q.queries):cap(q.queries)] {
+		if v != (searchQueryElement{}) {
+			t.Errorf("Expected the spare capacity of the query to be untouched, found '%v'", v)
+		}
+	}
+
+	if q.String() != "term=bar&location=Delft" {
+		t.Errorf("Expected the query to be unchanged, got '%s'", q.String())
+	}
+}
+
+func TestSearchQueryAccessors(t *testing.T) {
+	var q SearchQuery
+	SearchLocationCoordinates{"Delft", 52, 4.35}.Query(&q)
+	SearchLimit(10).Query(&q)
+	q.Append("custom", "a")
+
+	if value, ok := q.Get("limit"); !ok || value != "10" || !q.Has("cll") || q.Has("term") {
+		t.Errorf("Expected the limit and hint to be present, got '%s'", q.String())
+	}
+
+	if options := q.Options(); len(options) != 2 || options[0] != "limit" || options[1] != "location" {
+		t.Errorf("Expected the limit and location options to be set, got '%v'", options)
+	}
+
+	//replacing the location removes all of its elements
+	q.Set("ll", "51.9,4.4")
+	if q.String() != "limit=10&custom=a&ll=51.9,4.4" || SearchLocation("Delft").Query(&q) == nil {
+		t.Errorf("Expected the location to be replaced, got '%s'", q.String())
+	}
+
+	//the hint only replaces the hint
+	q.Set("location", "Delft")
+	q.Set("cll", "52,4.35")
+	q.Set("custom", "b")
+	if q.String() != "limit=10&location=Delft&cll=52,4.35&custom=b" {
+		t.Errorf("Expected the hint to accompany the location, got '%s'", q.String())
+	}
+
+	q.Set("term", "bar")
+	if !q.HasOption("term") || (SearchTerms{"bar"}).Query(&q) == nil {
+		t.Errorf("Expected setting the term to set the term option")
+	}
+
+	q.Delete("limit")
+	if q.HasOption("limit") || SearchLimit(5).Query(&q) != nil {
+		t.Errorf("Expected the limit to be settable after deleting it")
+	}
+
+	//the hint does not set the location by itself
+	var h SearchQuery
+	h.Set("cll", "52,4.35")
+	h = h.With("cll", "52,4.36")
+	if h.HasOption("location") || SearchLocation("Delft").Query(&h) != nil || len(h.validate()) != 0 {
+		t.Errorf("Expected the location to be settable after setting the hint, got '%s'", h.String())
+	}
+
+	//removing the location does not leave it set through the hint
+	h = h.Without("location")
+	if h.HasOption("location") {
+		t.Errorf("Expected the location not to be set by a remaining hint")
+	}
+}
+
+func TestClientDefaults(t *testing.T) {
+	var query SearchQuery
+	c := stubClient(t, func(r *Request) (*Response, error) {
+		query = r.Query
+		return &Response{Businesses: &Businesses{}}, nil
+	})
+
+	if c.SetDefaults(SearchLocation("Delft"), SearchCoordinates{52, 4.35}) == nil {
+		t.Errorf("Expected repeated defaults to be rejected")
+	}
+
+	if err := c.SetDefaults(SearchLocation("Delft"), SearchRadius(2000), SearchLimit(10)); err != nil {
+		t.Fatalf("Failed to set the defaults: %v", err)
+	}
+
+	c.SearchOptions(SearchTerms{"bar"})
+	if query.String() != "term=bar&location=Delft&radius_filter=2000&limit=10" {
+		t.Errorf("Expected the defaults to be added, got '%s'", query.String())
+	}
+
+	//options of the same kind override the defaults
+	if _, err := c.SearchOptions(SearchCoordinates{52, 4.35}, SearchLimit(5)); err != nil {
+		t.Fatalf("Expected overriding the defaults to succeed, got '%v'", err)
+	}
+
+	if query.String() != "ll=52,4.35&limit=5&radius_filter=2000" {
+		t.Errorf("Expected the location and limit to be overridden, got '%s'", query.String())
+	}
+
+	//defaults conflicting with the options are dropped
+	if _, err := c.SearchOptions(SearchBounds{51, 4, 52, 5}, SearchOffset(35)); err != nil {
+		t.Fatalf("Expected the conflicting defaults to be dropped, got '%v'", err)
+	}
+
+	if query.String() != "bounds=51,4|52,5&offset=35" {
+		t.Errorf("Expected the radius and limit to be dropped, got '%s'", query.String())
+	}
+
+	//the country code and language are overridden together
+	if err := c.SetDefaults(SearchLocation("Delft"), SearchCountryCode("NL"), SearchLanguage("nl")); err != nil {
+		t.Fatalf("Failed to set the defaults: %v", err)
+	}
+
+	if _, err := c.SearchOptions(SearchLanguage("de")); err != nil {
+		t.Fatalf("Expected the locale defaults to be overridden, got '%v'", err)
+	}
+
+	if query.String() != "lang=de&location=Delft" {
+		t.Errorf("Expected the country code and language to be dropped, got '%s'", query.String())
+	}
+
+	if _, err := c.SearchOptions(SearchCountryCode("DE")); err != nil || query.String() != "cc=DE&location=Delft" {
+		t.Errorf("Expected the locale defaults to be overridden, got '%s', '%v'", query.String(), err)
+	}
+
+	//conflicts within the options are left to the caller, as without defaults
+	if _, err := c.SearchOptions(SearchRadius(1000), SearchBounds{51, 4, 52, 5}); err != nil {
+		t.Errorf("Expected the options to be treated as by a client without defaults, got '%v'", err)
+	}
+
+	if query.String() != "radius_filter=1000&bounds=51,4|52,5&cc=NL&lang=nl" {
+		t.Errorf("Expected the remaining defaults to be added, got '%s'", query.String())
+	}
+}
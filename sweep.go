@@ -0,0 +1,224 @@
+package yelp
+
+import (
+	"math"
+)
+
+//The searchMaxResults constant is the maximum number of businesses Yelp allows
+//to be retrieved for a single search, regardless of the used limit and offset.
+//The searchMaxLimit constant is the maximum number of businesses Yelp returns
+//in a single response.
+const (
+	searchMaxResults = 40
+	searchMaxLimit   = 20
+)
+
+//sweepMinSpan is the default smallest tile height and width in degrees (roughly
+//100 meters) below which an area sweep will not subdivide any further
+const sweepMinSpan float64 = 0.001
+
+//The AreaSweep structure describes an exhaustive search of a (possibly large)
+//region. As Yelp caps the number of businesses that can be retrieved for a
+//single search, the sweep subdivides the region into tiles until every tile
+//contains few enough businesses to be retrieved entirely. The sweep is
+//performed through Client.Sweep(...).
+//
+//The Options are added to the search of every tile and may therefore not
+//specify a location, limit or offset, nor anything that can not be combined
+//with bounds such as a radius. MaxResults is the maximum number of
+//businesses that can be retrieved for a single search, and MinSpan is the
+//smallest tile height and width in degrees. When zero, these default to the
+//Yelp maximum and roughly 100 meters respectively.
+type AreaSweep struct {
+	Bounds     SearchBounds
+	Options    []SearchQuerier
+	MaxResults int
+	MinSpan    float64
+}
+
+//The SweepResult structure contains the deduplicated businesses found by an
+//area sweep together with statistics on how they were obtained. Coverage is
+//the fraction of the swept area of which all businesses could be retrieved.
+//Tiles that still contained too many businesses at the smallest tile size are
+//listed in Incomplete.
+type SweepResult struct {
+	Businesses *Businesses
+	Requests   int
+	Tiles      int
+	Splits     int
+	Coverage   float64
+	Incomplete []Bounds
+}
+
+//searchFunc is the signature of a function performing a single search, such
+//as Client.SearchOptions. It allows the sweep to be performed without a Client
+type searchFunc func(options ...SearchQuerier) (*Businesses, error)
+
+//Sweep performs an exhaustive search of the region described by the AreaSweep,
+//subdividing it into tiles whenever a tile contains more businesses than can be
+//retrieved, and paginating through every tile. Businesses appearing in multiple
+//tiles are only returned once, where they are matched in the same manner as in
+//a ResultSet. The default options of the client are not used,
+//all options have to be provided through the AreaSweep.
+func (c Client) Sweep(s AreaSweep) (*SweepResult, error) {
+	return s.run(c.withoutDefaults().SearchOptions)
+}
+
+//validate checks the area sweep definition and returns it with the default
+//values filled in
+func (s AreaSweep) validate() (AreaSweep, error) {
+	if err := s.Bounds.Bounds().validate("AreaSweep"); err != nil {
+		return s, err
+	}
+
+	//the options may not contain anything the sweep sets itself
+	var q SearchQuery
+
+	for _, v := range s.Options {
+		if err := v.Query(&q); err != nil {
+			return s, err
+		}
+	}
+
+	if q.mask&(searchBitMaskLocation|searchBitMaskLimit|searchBitMaskOffset) != 0 {
+		return s, Error{ErrorTypeInvalidArgumentRepetition, "AreaSweep", "Options may not specify a location, limit or offset"}
+	}
+
+	//the options have to be valid together with the bounds of every tile, which
+	//for example rules out a radius
+	if err := s.Bounds.Query(&q); err != nil {
+		return s, err
+	}
+
+	if errs := q.validate(); len(errs) != 0 {
+		return s, ValidationErrors(errs)
+	}
+
+	if s.MaxResults < 0 || s.MinSpan < 0 {
+		return s, Error{ErrorTypeInvalidArgumentDefinition, "AreaSweep", "Maximum results and minimum span may not be negative"}
+	}
+
+	if s.MaxResults == 0 {
+		s.MaxResults = searchMaxResults
+	}
+
+	if s.MinSpan == 0 {
+		s.MinSpan = sweepMinSpan
+	}
+
+	return s, nil
+}
+
+//run performs the area sweep using the provided search function
+func (s AreaSweep) run(search searchFunc) (*SweepResult, error) {
+	s, err := s.validate()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SweepResult{}
+	found := NewResultSet()
+
+	region := s.Bounds.Bounds()
+	totalArea := area(region)
+	coveredArea := 0.0
+	pageSize := min(searchMaxLimit, s.MaxResults)
+	tiles := []Bounds{region}
+
+	for len(tiles) != 0 {
+		tile := tiles[len(tiles)-1]
+		tiles = tiles[:len(tiles)-1]
+
+		//retrieve the first page, which also tells how many businesses the
+		//tile contains
+		page, err := search(s.tileOptions(tile, pageSize, 0)...)
+		result.Requests++
+
+		if err != nil {
+			return nil, err
+		}
+
+		found.Add("sweep", page)
+
+		complete := page.Total <= s.MaxResults
+
+		if s.split(tile, page.Total) {
+			//too many businesses, subdivide the tile
+			tiles = append(tiles, quadrants(tile)...)
+			result.Splits++
+			continue
+		}
+
+		//retrieve the remaining pages of the tile
+		for offset := pageSize; offset < min(page.Total, s.MaxResults); offset += pageSize {
+			next, err := search(s.tileOptions(tile, min(pageSize, s.MaxResults-offset), offset)...)
+			result.Requests++
+
+			if err != nil {
+				return nil, err
+			}
+
+			found.Add("sweep", next)
+		}
+
+		result.Tiles++
+
+		if complete {
+			coveredArea += area(tile)
+		} else {
+			result.Incomplete = append(result.Incomplete, tile)
+		}
+	}
+
+	//the region is the swept region rather than the one spanned by the results
+	regionCopy := region.Region()
+	result.Businesses = found.Businesses()
+	result.Businesses.Region = &regionCopy
+
+	if totalArea > 0 {
+		result.Coverage = math.Min(1, coveredArea/totalArea)
+	}
+
+	return result, nil
+}
+
+//split returns true when a tile containing the provided total number of
+//businesses should be subdivided further
+func (s AreaSweep) split(tile Bounds, total int) bool {
+	span := tile.Span()
+	return total > s.MaxResults && (span.Latitude > s.MinSpan || span.Longitude > s.MinSpan)
+}
+
+//tileOptions returns the search options used to search a single tile
+func (s AreaSweep) tileOptions(tile Bounds, limit, offset int) []SearchQuerier {
+	options := make([]SearchQuerier, 0, len(s.Options)+3)
+	options = append(options, s.Options...)
+	options = append(options, tile.SearchBounds(), SearchLimit(limit))
+
+	if offset != 0 {
+		options = append(options, SearchOffset(offset))
+	}
+
+	return options
+}
+
+//quadrants splits a tile into its four quadrants, taking tiles crossing the
+//antimeridian into account
+func quadrants(b Bounds) []Bounds {
+	center := b.Center()
+
+	return []Bounds{
+		{b.SouthWest, center},
+		{Coordinates{b.SouthWest.Latitude, center.Longitude}, Coordinates{center.Latitude, b.NorthEast.Longitude}},
+		{Coordinates{center.Latitude, b.SouthWest.Longitude}, Coordinates{b.NorthEast.Latitude, center.Longitude}},
+		{center, b.NorthEast},
+	}
+}
+
+//area returns the surface area of the bounding box on the unit sphere. Only
+//ratios of areas are used, so the radius of the earth is left out
+func area(b Bounds) float64 {
+	return toRadians(b.Span().Longitude) *
+		(math.Sin(toRadians(b.NorthEast.Latitude)) - math.Sin(toRadians(b.SouthWest.Latitude)))
+}
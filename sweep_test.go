@@ -0,0 +1,195 @@
+package yelp
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+//fakeSearch returns a search function serving the provided businesses from
+//memory, respecting the bounds, limit and offset search options
+func fakeSearch(all []*Business, requests *int) searchFunc {
+	return func(options ...SearchQuerier) (*Businesses, error) {
+		*requests++
+
+		var bounds Bounds
+		limit, offset := searchMaxLimit, 0
+
+		for _, v := range options {
+			switch o := v.(type) {
+			case SearchBounds:
+				bounds = o.Bounds()
+			case SearchLimit:
+				limit = int(o)
+			case SearchOffset:
+				offset = int(o)
+			}
+		}
+
+		var inside []*Business
+		for _, v := range all {
+			if bounds.Contains(v.Location.Position) {
+				inside = append(inside, v)
+			}
+		}
+
+		result := &Businesses{Total: len(inside)}
+		for i := offset; i < len(inside) && i < offset+limit && i < searchMaxResults; i++ {
+			result.Businesses = append(result.Businesses, inside[i])
+		}
+
+		return result, nil
+	}
+}
+
+//gridBusinesses creates a grid of 20 by 20 businesses within the bounds
+//(0, 0) to (2, 2)
+func gridBusinesses() []*Business {
+	var all []*Business
+	for i := 0; i < 20; i++ {
+		for j := 0; j < 20; j++ {
+			all = append(all, &Business{ID: strconv.Itoa(i*20 + j),
+				Location: &BusinessLocation{Position: Coordinates{0.05 + 0.1*float64(i), 0.05 + 0.1*float64(j)}}})
+		}
+	}
+
+	return all
+}
+
+func TestAreaSweep(t *testing.T) {
+	all := gridBusinesses()
+	requests := 0
+	sweep := AreaSweep{Bounds: SearchBounds{0, 0, 2, 2}, Options: []SearchQuerier{SearchTerms{"bar"}}}
+	result, err := sweep.run(fakeSearch(all, &requests))
+
+	if err != nil {
+		t.Fatalf("Expected sweep to succeed: %v", err)
+	}
+
+	if result.Businesses.Total != len(all) || len(result.Businesses.Businesses) != len(all) {
+		t.Errorf("Expected sweep to find all %d businesses, found %d", len(all), result.Businesses.Total)
+	}
+
+	if result.Requests != requests {
+		t.Errorf("Expected %d requests to be reported, got %d", requests, result.Requests)
+	}
+
+	if math.Abs(result.Coverage-1) > 1e-9 || len(result.Incomplete) != 0 {
+		t.Errorf("Expected full coverage, got %f with %d incomplete tiles", result.Coverage, len(result.Incomplete))
+	}
+
+	if result.Splits == 0 {
+		t.Errorf("Expected the region to be subdivided")
+	}
+}
+
+func TestAreaSweepMatchesResultSet(t *testing.T) {
+	//the same business reported with and without an ID is only returned once
+	all := append(gridBusinesses(),
+		&Business{Name: "Cafe", Location: &BusinessLocation{Position: Coordinates{0.52, 0.52}}},
+		&Business{ID: "cafe", Name: "cafe", Location: &BusinessLocation{Position: Coordinates{0.5201, 0.52}}})
+	requests := 0
+	result, err := AreaSweep{Bounds: SearchBounds{0, 0, 2, 2}}.run(fakeSearch(all, &requests))
+
+	if err != nil {
+		t.Fatalf("Expected sweep to succeed: %v", err)
+	}
+
+	if len(result.Businesses.Businesses) != len(all)-1 {
+		t.Errorf("Expected sweep to find %d distinct businesses, found %d", len(all)-1, len(result.Businesses.Businesses))
+	}
+}
+
+func TestAreaSweepInvalidOptions(t *testing.T) {
+	invalid := [][]SearchQuerier{{SearchLocation("Delft")}, {SearchLimit(5)}, {SearchOffset(5)},
+		{SearchRadius(1000)}, {SearchParam("cll", "52,4")}}
+
+	for _, v := range invalid {
+		requests := 0
+		sweep := AreaSweep{Bounds: SearchBounds{0, 0, 1, 1}, Options: v}
+
+		if _, err := sweep.run(fakeSearch(nil, &requests)); err == nil || requests != 0 {
+			t.Errorf("Expected sweep with options '%v' to fail without requests, got %d requests", v, requests)
+		}
+	}
+}
+
+func TestEstimateSweep(t *testing.T) {
+	all := gridBusinesses()
+	sweep := AreaSweep{Bounds: SearchBounds{0, 0, 2, 2}}
+
+	requests := 0
+	result, err := sweep.run(fakeSearch(all, &requests))
+	if err != nil {
+		t.Fatalf("Expected sweep to succeed: %v", err)
+	}
+
+	probes := 0
+	estimate, err := sweep.estimate(fakeSearch(all, &probes))
+	if err != nil {
+		t.Fatalf("Expected estimate to succeed: %v", err)
+	}
+
+	if estimate.Requests != result.Requests || estimate.Tiles != result.Tiles {
+		t.Errorf("Expected estimate of %d requests in %d tiles, sweep took %d requests in %d tiles",
+			estimate.Requests, estimate.Tiles, result.Requests, result.Tiles)
+	}
+
+	if estimate.Probes != probes || estimate.Businesses < len(all) {
+		t.Errorf("Expected %d probes for at least %d businesses, got %d probes for %d businesses",
+			probes, len(all), estimate.Probes, estimate.Businesses)
+	}
+}
+
+func TestSweepIgnoresDefaults(t *testing.T) {
+	var queries []SearchQuery
+	c := stubClient(t, func(r *Request) (*Response, error) {
+		queries = append(queries, r.Query)
+		return &Response{Businesses: &Businesses{}}, nil
+	})
+
+	if err := c.SetDefaults(SearchLocation("Delft"), SearchRadius(2000), SearchOffset(20), SearchTerms{"bar"}); err != nil {
+		t.Fatalf("Failed to set the defaults: %v", err)
+	}
+
+	sweep := AreaSweep{Bounds: SearchBounds{0, 0, 2, 2}}
+	if _, err := c.Sweep(sweep); err != nil {
+		t.Fatalf("Expected the sweep to succeed, got '%v'", err)
+	}
+
+	if _, err := c.EstimateSweep(sweep); err != nil {
+		t.Fatalf("Expected the estimate to succeed, got '%v'", err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("Expected a single request for the sweep and the estimate, got %d", len(queries))
+	}
+
+	for _, v := range queries {
+		if v.Has("radius_filter") || v.Has("term") || v.Has("location") {
+			t.Errorf("Expected the defaults not to be used, got '%s'", v.String())
+		}
+
+		if offset, ok := v.Get("offset"); ok && offset != "0" {
+			t.Errorf("Expected the first page to start at offset 0, got '%s'", offset)
+		}
+	}
+}
+
+func TestEstimateSearchesParams(t *testing.T) {
+	var query SearchQuery
+	c := stubClient(t, func(r *Request) (*Response, error) {
+		query = r.Query
+		return &Response{Businesses: &Businesses{Total: 30}}, nil
+	})
+
+	//a limit and offset set through SearchParam are replaced as well
+	estimate, err := c.EstimateSearches([]SearchQuerier{SearchLocation("Delft"), SearchParam("limit", "5"), SearchParam("offset", "20")})
+	if err != nil {
+		t.Fatalf("Expected the estimate to succeed, got '%v'", err)
+	}
+
+	if query.String() != "location=Delft&limit=1" || estimate.Requests != 2 {
+		t.Errorf("Expected a probe limited to a single business, got '%s' for %d requests", query.String(), estimate.Requests)
+	}
+}
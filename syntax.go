@@ -0,0 +1,564 @@
+package yelp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//searchSortAliases contains the short names of the sorting methods used by the
+//search syntax, indexed by their SearchSort value. The names in searchSortNames
+//are accepted as well
+var searchSortAliases = [...]string{"best", "distance", "rating"}
+
+//The ParseError structure is returned by ParseSearch(...) when the search can
+//not be parsed. Position is the zero-based byte offset in the input of the
+//token or value that caused the error. The underlying Error can be retrieved
+//through errors.As(...).
+type ParseError struct {
+	Position int
+	err      Error
+}
+
+//newParseError creates a new ParseError at the provided position
+func newParseError(position int, err error) ParseError {
+	var e Error
+
+	if !errors.As(err, &e) {
+		e = Error{ErrorTypeInvalidArgumentDefinition, "ParseSearch", err.Error()}
+	}
+
+	return ParseError{position, e}
+}
+
+func (e ParseError) Error() string {
+	return Error{e.err.EType, e.err.source, fmt.Sprintf("Position %d: %s", e.Position, e.err.message)}.Error()
+}
+
+//Unwrap returns the underlying Error
+func (e ParseError) Unwrap() error {
+	return e.err
+}
+
+//The searchToken structure is a single whitespace separated token of a search
+//in the search syntax, for example 'near:"Delft"'. Bare words have no key. The
+//offsets contain the position in the input of every byte of the decoded value,
+//as quotes and escapes make the value differ from the input
+type searchToken struct {
+	position      int
+	key           string
+	value         string
+	valuePosition int
+	offsets       []int
+	end           int
+}
+
+//offset returns the position in the input of the byte at the provided index in
+//the decoded value, or the end of the token when the index is past the value
+func (t searchToken) offset(index int) int {
+	if index < len(t.offsets) {
+		return t.offsets[index]
+	}
+
+	return t.end
+}
+
+//tokenizeSearch splits a search into its tokens. Double quotes group words
+//containing whitespace, within them a backslash escapes the next character. A
+//quoted value may be empty
+func tokenizeSearch(input string) ([]searchToken, error) {
+	var tokens []searchToken
+
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+
+		token := searchToken{position: i, valuePosition: i}
+		var value strings.Builder
+		hasKey, quoted := false, false
+
+		//write adds a decoded rune to the value, originating at the provided
+		//position in the input
+		write := func(r rune, at int) {
+			length := value.Len()
+			value.WriteRune(r)
+
+			for j := length; j < value.Len(); j++ {
+				token.offsets = append(token.offsets, at)
+			}
+		}
+
+		for i < len(input) {
+			r, size = utf8.DecodeRuneInString(input[i:])
+
+			if unicode.IsSpace(r) {
+				break
+			}
+
+			switch {
+			case r == '"':
+				//read the quoted text up to and including the closing quote
+				start, closed := i, false
+				quoted = true
+				i += size
+
+				for i < len(input) && !closed {
+					r, size = utf8.DecodeRuneInString(input[i:])
+					at := i
+					i += size
+
+					switch {
+					case r == '"':
+						closed = true
+					case r == '\\' && i < len(input):
+						r, size = utf8.DecodeRuneInString(input[i:])
+						write(r, i)
+						i += size
+					default:
+						write(r, at)
+					}
+				}
+
+				if !closed {
+					return nil, newParseError(start, Error{ErrorTypeInvalidArgumentDefinition, "ParseSearch", "Unterminated quote"})
+				}
+
+				continue
+			case r == ':' && !hasKey && !quoted:
+				//the text up to the first colon is the key
+				token.key = strings.ToLower(value.String())
+				token.valuePosition = i + size
+				token.offsets = nil
+				value.Reset()
+				hasKey = true
+
+				if token.key == "" {
+					return nil, newParseError(i, Error{ErrorTypeInvalidArgumentDefinition, "ParseSearch", "Missing key before ':'"})
+				}
+			default:
+				write(r, i)
+			}
+
+			i += size
+		}
+
+		token.value = value.String()
+		token.end = i
+
+		if hasKey && token.value == "" && !quoted {
+			return nil, newParseError(token.valuePosition, Error{ErrorTypeInvalidArgumentDefinition, "ParseSearch",
+				fmt.Sprintf("Missing value for '%s'", token.key)})
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+//The parsedOption structure is a search option together with the position in
+//the input where it was specified
+type parsedOption struct {
+	position int
+	option   SearchQuerier
+}
+
+//ParseSearch parses a search written in the search syntax into search options.
+//A search consists of whitespace separated words and key:value pairs, where
+//double quotes group text containing whitespace:
+//
+//	sushi pizza near:"Delft" cat:restaurants radius:2km sort:rating limit:10 deals
+//
+//Words without a key are the search terms (SearchTerms). The supported keys
+//are:
+//
+//- near:<name> for SearchLocation, or SearchLocationCoordinates together with at
+//- at:<latitude>,<longitude> for SearchCoordinates
+//- bounds:<sw latitude>,<sw longitude>,<ne latitude>,<ne longitude> for SearchBounds
+//- cat:<category>,... for SearchCategories, using the Yelp category names
+//- radius:<distance> for SearchRadius, in meters or with a m or km suffix
+//- sort:<best|distance|rating> for SearchSort
+//- limit:<n> and offset:<n> for SearchLimit and SearchOffset
+//- deals, or deals:<true|false>, for SearchDeals
+//- cc:<code> and lang:<code> for SearchCountryCode and SearchLanguage
+//- lang_filter:<true|false> and actionlinks:<true|false> for
+//SearchLanguageFilter and SearchActionLinks
+//
+//The word "deals" can be searched for by quoting it. Every option is validated,
+//when any of them is invalid a ParseError pointing at it is returned.
+func ParseSearch(input string) ([]SearchQuerier, error) {
+	tokens, err := tokenizeSearch(input)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var options []parsedOption
+	var terms SearchTerms
+	var near, at, bounds *searchToken
+	termsPosition := 0
+	seen := make(map[string]bool)
+
+	for i := range tokens {
+		token := &tokens[i]
+
+		//terms and the deals flag have no key
+		if token.key == "" && token.value == "deals" && input[token.position] != '"' {
+			token.key = "deals"
+			token.value = "true"
+		}
+
+		if token.key == "" {
+			if len(terms) == 0 {
+				termsPosition = token.position
+			}
+
+			terms = append(terms, token.value)
+			continue
+		}
+
+		if seen[token.key] {
+			return nil, newParseError(token.position, Error{ErrorTypeInvalidArgumentRepetition, "ParseSearch",
+				fmt.Sprintf("'%s' is specified more than once", token.key)})
+		}
+
+		seen[token.key] = true
+		option, err := parseSearchToken(*token)
+
+		if err != nil {
+			return nil, err
+		}
+
+		//the location is assembled afterwards, as near and at may be combined
+		switch token.key {
+		case "near":
+			near = token
+		case "at":
+			at = token
+		case "bounds":
+			bounds = token
+		default:
+			options = append(options, parsedOption{token.position, option})
+		}
+	}
+
+	if len(terms) != 0 {
+		options = append(options, parsedOption{termsPosition, terms})
+	}
+
+	location, err := parseSearchLocation(near, at, bounds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if location.option != nil {
+		options = append(options, location)
+	}
+
+	//validate the options in the order in which they were specified
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].position < options[j].position
+	})
+
+	var q SearchQuery
+	result := make([]SearchQuerier, len(options))
+
+	for i, v := range options {
+		if err := v.option.Query(&q); err != nil {
+			return nil, newParseError(v.position, err)
+		}
+
+		result[i] = v.option
+	}
+
+	return result, nil
+}
+
+//parseSearchToken converts a single key:value token into a search option. The
+//location tokens are parsed to check their values, but combined afterwards
+func parseSearchToken(token searchToken) (SearchQuerier, error) {
+	invalid := func(format string) error {
+		return newParseError(token.valuePosition, Error{ErrorTypeInvalidArgumentDefinition, "ParseSearch",
+			fmt.Sprintf(format, token.value, token.key)})
+	}
+
+	switch token.key {
+	case "near":
+		return SearchLocation(token.value), nil
+	case "at":
+		values, ok := parseSearchFloats(token.value, 2)
+
+		if !ok {
+			return nil, invalid("Invalid coordinates '%s' for '%s', expected <latitude>,<longitude>")
+		}
+
+		return SearchCoordinates{values[0], values[1]}, nil
+	case "bounds":
+		values, ok := parseSearchFloats(token.value, 4)
+
+		if !ok {
+			return nil, invalid("Invalid bounds '%s' for '%s', expected four comma separated coordinates")
+		}
+
+		return SearchBounds{values[0], values[1], values[2], values[3]}, nil
+	case "cat":
+		var categories SearchCategories
+		index := 0
+
+		for _, v := range strings.Split(token.value, ",") {
+			category, ok := parseSearchCategory(v)
+
+			if !ok {
+				return nil, newParseError(token.offset(index), Error{ErrorTypeInvalidArgumentDefinition, "ParseSearch",
+					fmt.Sprintf("Unknown category '%s'", v)})
+			}
+
+			categories = append(categories, category)
+			index += len(v) + 1
+		}
+
+		return categories, nil
+	case "radius":
+		meters, ok := parseSearchDistance(token.value)
+
+		if !ok {
+			return nil, invalid("Invalid distance '%s' for '%s', expected e.g. 500m or 2km")
+		}
+
+		return SearchRadius(meters), nil
+	case "sort":
+		for i, v := range searchSortAliases {
+			if v == token.value {
+				return SearchSort(i), nil
+			}
+		}
+
+		if method, ok := parseSearchSort(token.value); ok {
+			return method, nil
+		}
+
+		return nil, invalid("Unknown sorting method '%s' for '%s', expected best, distance or rating")
+	case "limit", "offset":
+		value, err := strconv.Atoi(token.value)
+
+		if err != nil {
+			return nil, invalid("Invalid number '%s' for '%s'")
+		}
+
+		if token.key == "limit" {
+			return SearchLimit(value), nil
+		}
+
+		return SearchOffset(value), nil
+	case "deals", "lang_filter", "actionlinks":
+		value, err := strconv.ParseBool(token.value)
+
+		if err != nil {
+			return nil, invalid("Invalid boolean '%s' for '%s', expected true or false")
+		}
+
+		switch token.key {
+		case "deals":
+			return SearchDeals(value), nil
+		case "lang_filter":
+			return SearchLanguageFilter(value), nil
+		default:
+			return SearchActionLinks(value), nil
+		}
+	case "cc":
+		return SearchCountryCode(token.value), nil
+	case "lang":
+		return SearchLanguage(token.value), nil
+	}
+
+	return nil, newParseError(token.position, Error{ErrorTypeInvalidArgumentDefinition, "ParseSearch",
+		fmt.Sprintf("Unknown key '%s'", token.key)})
+}
+
+//parseSearchLocation combines the location tokens into a single location
+//option. A name together with coordinates is a SearchLocationCoordinates
+func parseSearchLocation(near, at, bounds *searchToken) (parsedOption, error) {
+	if bounds != nil && (near != nil || at != nil) {
+		//report the conflict at the last of the location tokens
+		position := bounds.position
+
+		for _, v := range []*searchToken{near, at} {
+			if v != nil {
+				position = max(position, v.position)
+			}
+		}
+
+		return parsedOption{}, newParseError(position, Error{ErrorTypeInvalidArgumentRepetition, "ParseSearch",
+			"Bounds can not be combined with another location"})
+	}
+
+	//the values have already been validated by parseSearchToken
+	switch {
+	case bounds != nil:
+		option, _ := parseSearchToken(*bounds)
+		return parsedOption{bounds.position, option}, nil
+	case near != nil && at != nil:
+		coordinates, _ := parseSearchFloats(at.value, 2)
+		option := SearchLocationCoordinates{near.value, coordinates[0], coordinates[1]}
+		return parsedOption{min(near.position, at.position), option}, nil
+	case near != nil:
+		return parsedOption{near.position, SearchLocation(near.value)}, nil
+	case at != nil:
+		option, _ := parseSearchToken(*at)
+		return parsedOption{at.position, option}, nil
+	}
+
+	return parsedOption{}, nil
+}
+
+//parseSearchFloats parses a comma separated list of exactly count numbers
+func parseSearchFloats(text string, count int) ([]float64, bool) {
+	fields := strings.Split(text, ",")
+
+	if len(fields) != count {
+		return nil, false
+	}
+
+	values := make([]float64, count)
+
+	for i, v := range fields {
+		value, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, false
+		}
+
+		values[i] = value
+	}
+
+	return values, true
+}
+
+//parseSearchDistance parses a distance in meters, which may have a m or km
+//suffix, and returns it in whole meters
+func parseSearchDistance(text string) (int, bool) {
+	scale := 1.0
+
+	switch {
+	case strings.HasSuffix(text, "km"):
+		text, scale = strings.TrimSuffix(text, "km"), 1000
+	case strings.HasSuffix(text, "m"):
+		text = strings.TrimSuffix(text, "m")
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) || math.Abs(value*scale) > math.MaxInt32 {
+		return 0, false
+	}
+
+	return int(math.Round(value * scale)), true
+}
+
+//FormatSearch renders search options in the search syntax accepted by
+//ParseSearch(...), such that the result can be shown to users or stored as
+//text. An error is returned for options that can not be expressed in the
+//syntax, such as those created by SearchParam(...).
+func FormatSearch(options ...SearchQuerier) (string, error) {
+	parts := make([]string, 0, len(options))
+
+	for _, v := range options {
+		switch o := v.(type) {
+		case SearchTerms:
+			for _, w := range o {
+				if w == "deals" || strings.ContainsRune(w, ':') {
+					parts = append(parts, quoteSearchValue(w, true))
+				} else {
+					parts = append(parts, quoteSearchValue(w, false))
+				}
+			}
+		case SearchLocation:
+			parts = append(parts, "near:"+quoteSearchValue(string(o), false))
+		case SearchLocationCoordinates:
+			parts = append(parts, "near:"+quoteSearchValue(o.Location, false), "at:"+formatSearchFloats(o.Latitude, o.Longitude))
+		case SearchCoordinates:
+			parts = append(parts, "at:"+formatSearchFloats(o.Latitude, o.Longitude))
+		case SearchBounds:
+			parts = append(parts, "bounds:"+formatSearchFloats(o.SWLatitude, o.SWLongitude, o.NELatitude, o.NELongitude))
+		case SearchCategories:
+			names := make([]string, len(o))
+
+			for i, w := range o {
+				if !w.Valid() {
+					return "", Error{ErrorTypeInvalidArgumentDefinition, "FormatSearch", "Invalid search category specified"}
+				}
+
+				names[i] = w.String()
+			}
+
+			parts = append(parts, "cat:"+strings.Join(names, ","))
+		case SearchRadius:
+			if o != 0 && o%1000 == 0 {
+				parts = append(parts, fmt.Sprintf("radius:%dkm", int(o)/1000))
+			} else {
+				parts = append(parts, fmt.Sprintf("radius:%dm", int(o)))
+			}
+		case SearchSort:
+			if o < SearchSortBestMatched || o > SearchSortHighestRated {
+				return "", Error{ErrorTypeInvalidArgumentDefinition, "FormatSearch", fmt.Sprintf("Invalid sorting method: %v", o)}
+			}
+
+			parts = append(parts, "sort:"+searchSortAliases[o])
+		case SearchLimit:
+			parts = append(parts, "limit:"+strconv.Itoa(int(o)))
+		case SearchOffset:
+			parts = append(parts, "offset:"+strconv.Itoa(int(o)))
+		case SearchDeals:
+			if o {
+				parts = append(parts, "deals")
+			} else {
+				parts = append(parts, "deals:false")
+			}
+		case SearchCountryCode:
+			parts = append(parts, "cc:"+quoteSearchValue(string(o), false))
+		case SearchLanguage:
+			parts = append(parts, "lang:"+quoteSearchValue(string(o), false))
+		case SearchLanguageFilter:
+			parts = append(parts, "lang_filter:"+strconv.FormatBool(bool(o)))
+		case SearchActionLinks:
+			parts = append(parts, "actionlinks:"+strconv.FormatBool(bool(o)))
+		default:
+			return "", Error{ErrorTypeInvalidArgumentDefinition, "FormatSearch", fmt.Sprintf("Option %T can not be formatted", v)}
+		}
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+//quoteSearchValue returns the value as it should be written in the search
+//syntax, quoting it when it is empty, contains whitespace or quotes, or when
+//quoting is forced
+func quoteSearchValue(value string, force bool) string {
+	if !force && value != "" && !strings.ContainsAny(value, "\"\\") && strings.IndexFunc(value, unicode.IsSpace) == -1 {
+		return value
+	}
+
+	replacer := strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+	return "\"" + replacer.Replace(value) + "\""
+}
+
+//formatSearchFloats returns the values as a comma separated list
+func formatSearchFloats(values ...float64) string {
+	fields := make([]string, len(values))
+
+	for i, v := range values {
+		fields[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,107 @@
+package yelp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseSearch(t *testing.T) {
+	options, err := ParseSearch(`sushi pizza near:"Delft" cat:restaurants radius:2km sort:rating limit:10 deals`)
+	if err != nil {
+		t.Fatalf("Expected the search to be parsed, got '%v'", err)
+	}
+
+	expected := []SearchQuerier{SearchTerms{"sushi", "pizza"}, SearchLocation("Delft"),
+		SearchCategories{SearchCategoryRestaurants}, SearchRadius(2000), SearchSort(SearchSortHighestRated),
+		SearchLimit(10), SearchDeals(true)}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("Expected '%v', got '%v'", expected, options)
+	}
+
+	//a name and coordinates are combined, quoted words are terms
+	options, err = ParseSearch(`at:52,4.35 "happy hour" near:"Den \"Haag\"" "deals" radius:500`)
+	expected = []SearchQuerier{SearchLocationCoordinates{`Den "Haag"`, 52, 4.35}, SearchTerms{"happy hour", "deals"},
+		SearchRadius(500)}
+	if err != nil || !reflect.DeepEqual(options, expected) {
+		t.Errorf("Expected '%v', got '%v', '%v'", expected, options, err)
+	}
+}
+
+func TestParseSearchErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+	}{
+		{`bar near:"Delft`, 9},
+		{`bar cat:bars,pubs`, 13},
+		{`cat:"bars,pubs"`, 10},
+		{`cat:"ba\rs",pubs`, 12},
+		{`cat:bars,`, 9},
+		{`bar limit:50`, 4},
+		{`bar limit:ten`, 10},
+		{`near:Delft bar near:Leiden`, 15},
+		{`bounds:51,4,52,5 at:52,4`, 17},
+		{`bar color:red`, 4},
+		{`bar near:`, 9},
+		{`radius:far`, 7},
+		{`sort:price`, 5},
+		{`cc:NL lang:fr`, 6},
+	}
+
+	for _, v := range tests {
+		_, err := ParseSearch(v.input)
+
+		var e ParseError
+		if !errors.As(err, &e) || e.Position != v.position {
+			t.Errorf("Expected parsing '%s' to fail at position %d, got '%v'", v.input, v.position, err)
+		}
+	}
+
+	//the underlying error remains available
+	_, err := ParseSearch(`limit:50`)
+	var e Error
+	if !errors.As(err, &e) || e.source != "SearchLimit" {
+		t.Errorf("Expected the error of the option to be wrapped, got '%v'", err)
+	}
+}
+
+func TestFormatSearch(t *testing.T) {
+	options := []SearchQuerier{SearchTerms{"sushi", "happy hour", "deals"}, SearchLocationCoordinates{"Den Haag", 52.07, 4.3},
+		SearchCategories{SearchCategoryBars, SearchCategoryPubFood}, SearchRadius(1500), SearchSort(SearchSortDistance),
+		SearchLimit(10), SearchOffset(10), SearchDeals(true), SearchCountryCode("NL"), SearchLanguage("nl"),
+		SearchLanguageFilter(true), SearchActionLinks(false)}
+
+	text, err := FormatSearch(options...)
+	expected := `sushi "happy hour" "deals" near:"Den Haag" at:52.07,4.3 cat:bars,pubfood radius:1500m sort:distance ` +
+		`limit:10 offset:10 deals cc:NL lang:nl lang_filter:true actionlinks:false`
+	if err != nil || text != expected {
+		t.Errorf("Expected '%s', got '%s', '%v'", expected, text, err)
+	}
+
+	parsed, err := ParseSearch(text)
+	if err != nil || !reflect.DeepEqual(parsed, options) {
+		t.Errorf("Expected the formatted search to be parsed into the original options, got '%v', '%v'", parsed, err)
+	}
+
+	text, err = FormatSearch(SearchBounds{51, 4, 52, 5}, SearchRadius(2000))
+	if err != nil || text != "bounds:51,4,52,5 radius:2km" {
+		t.Errorf("Expected the bounds and radius to be formatted, got '%s', '%v'", text, err)
+	}
+
+	//empty values are quoted, such that they can be parsed
+	options = []SearchQuerier{SearchTerms{""}, SearchLocation("")}
+	text, err = FormatSearch(options...)
+	if err != nil || text != `"" near:""` {
+		t.Errorf("Expected the empty values to be quoted, got '%s', '%v'", text, err)
+	}
+
+	parsed, err = ParseSearch(text)
+	if err != nil || !reflect.DeepEqual(parsed, options) {
+		t.Errorf("Expected the empty values to be parsed into the original options, got '%v', '%v'", parsed, err)
+	}
+
+	if _, err = FormatSearch(SearchParam("attrs", "x")); err == nil {
+		t.Errorf("Expected formatting an arbitrary parameter to fail")
+	}
+}
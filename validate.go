@@ -0,0 +1,87 @@
+package yelp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//The ValidationErrors type lists every problem found by Validate(...). Each
+//error is usually an Error naming the option that caused it as its source. The
+//individual errors can be inspected through errors.Is(...) and errors.As(...).
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+
+	for i, v := range e {
+		messages[i] = v.Error()
+	}
+
+	return fmt.Sprintf("%d invalid search options:\n", len(e)) + strings.Join(messages, "\n")
+}
+
+//Unwrap returns the individual errors
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+//Validate checks all provided search options at once. Contrary to
+//Client.SearchOptions(...), which stops at the first invalid option, every
+//option is evaluated. Afterwards the rules involving multiple options are
+//checked:
+//
+//- The offset and limit may not exceed the number of businesses Yelp allows to
+//be retrieved for a single search (40)
+//- The "cll" hint may only accompany a "location"
+//- A radius can not be combined with bounds
+//
+//When any problem is found a ValidationErrors listing all of them is returned.
+func Validate(options ...SearchQuerier) error {
+	return NewSearch().With(options...).Err()
+}
+
+//validate checks the rules involving multiple query elements and returns every
+//violation
+func (q SearchQuery) validate() []error {
+	var errs []error
+
+	//the offset and limit may be missing, or be set through SearchParam
+	limit, hasLimit := q.intValue(searchLimitKey)
+	offset, _ := q.intValue(searchOffsetKey)
+
+	switch {
+	case hasLimit && offset+limit > searchMaxResults:
+		errs = append(errs, Error{ErrorTypeInvalidArgumentDefinition, "SearchLimit, SearchOffset",
+			fmt.Sprintf("Offset %d and limit %d exceed the maximum of %d retrievable businesses", offset, limit, searchMaxResults)})
+	case !hasLimit && offset >= searchMaxResults:
+		errs = append(errs, Error{ErrorTypeInvalidArgumentDefinition, "SearchOffset",
+			fmt.Sprintf("Offset %d exceeds the maximum of %d retrievable businesses", offset, searchMaxResults)})
+	}
+
+	if q.Has(searchCoordinatesHintKey) && !q.Has(searchLocationKey) {
+		errs = append(errs, Error{ErrorTypeInvalidArgumentDefinition, "SearchLocationCoordinates",
+			"The cll coordinates may only accompany a location"})
+	}
+
+	if q.Has(searchRadiusKey) && q.Has(searchBoundsKey) {
+		errs = append(errs, Error{ErrorTypeInvalidArgumentDefinition, "SearchRadius, SearchBounds",
+			"A radius can not be combined with bounds"})
+	}
+
+	return errs
+}
+
+//intValue returns the integer value of the first query element with the
+//provided name. The boolean return value is false when no such element exists
+//or its value is not an integer
+func (q SearchQuery) intValue(name string) (int, bool) {
+	value, ok := q.Get(name)
+
+	if !ok {
+		return 0, false
+	}
+
+	i, err := strconv.Atoi(value)
+	return i, err == nil
+}
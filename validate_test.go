@@ -0,0 +1,40 @@
+package yelp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate(SearchLocation("Delft"), SearchLimit(20), SearchOffset(20)); err != nil {
+		t.Errorf("Expected valid options to pass, got '%v'", err)
+	}
+
+	err := Validate(SearchLocation("Delft"), SearchCoordinates{52, 4.35}, SearchLimit(50), SearchRadius(-1),
+		SearchOffset(30), SearchLimit(20))
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 4 {
+		t.Fatalf("Expected 4 problems, got '%v'", err)
+	}
+
+	//every problem is reported with its option as the source
+	//the second limit is valid, as the first one was rejected
+	sources := []string{"SearchCoordinates", "SearchLimit", "SearchRadius", "SearchLimit, SearchOffset"}
+	for i, v := range errs {
+		var e Error
+		if !errors.As(v, &e) || e.source != sources[i] {
+			t.Errorf("Expected problem %d to originate from '%s', got '%v'", i, sources[i], v)
+		}
+	}
+
+	var e Error
+	if !errors.As(err, &e) || e.EType != ErrorTypeInvalidArgumentRepetition {
+		t.Errorf("Expected the individual errors to be reachable through errors.As")
+	}
+
+	err = Validate(SearchParam("cll", "52,4.35"), SearchBounds{51, 4, 52, 5}, SearchRadius(1000), SearchOffset(40))
+	if !errors.As(err, &errs) || len(errs) != 3 {
+		t.Errorf("Expected the hint, radius and offset to be rejected, got '%v'", err)
+	}
+}